@@ -0,0 +1,403 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AvroCompatibilityBackward 新schema可以读取旧schema写入的数据
+	AvroCompatibilityBackward = "BACKWARD"
+	// AvroCompatibilityForward 旧schema可以读取新schema写入的数据
+	AvroCompatibilityForward = "FORWARD"
+	// AvroCompatibilityFull 同时满足BACKWARD以及FORWARD
+	AvroCompatibilityFull = "FULL"
+)
+
+// avroDefaultPrecision 小数未指定精度时的默认精度，与mysql的decimal默认值一致
+const avroDefaultPrecision = 10
+
+// avroNameRE avro名称以及枚举symbol的格式
+var avroNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// AvroParam 生成avro schema可指定的参数
+type AvroParam struct {
+	// Namespace 默认命名空间
+	Namespace string
+	// TableNamespaces 表英文名->命名空间，优先于Namespace
+	TableNamespaces map[string]string
+}
+
+func (p *AvroParam) getNamespace(table string) string {
+	if p == nil {
+		return ""
+	}
+	if namespace, ok := p.TableNamespaces[table]; ok {
+		return namespace
+	}
+	return p.Namespace
+}
+
+// ToAvroSchema 将表转换为avro record schema
+// 日期时间使用timestamp-millis，小数使用带precision/scale的decimal，字符串枚举转换为avro enum，
+// 数字枚举使用long，可为空的字段使用["null", T]并默认为null
+func (d *DefaultMetaCenter) ToAvroSchema(ctx context.Context, table *Table, param *AvroParam) (string, error) {
+	_, fieldEnums := d.collectEnums(ctx, []*Table{table})
+	definedEnums := make(map[string]bool)
+	fields := make([]interface{}, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		if !avroNameRE.MatchString(field.Name) {
+			return "", fmt.Errorf("field(%s) of table(%s) is not a valid avro name", field.Name, table.Name)
+		}
+		var fieldType interface{}
+		switch d.getFieldKind(ctx, field) {
+		case fieldKindInt, fieldKindUInt:
+			fieldType = "long"
+		case fieldKindFloat:
+			fieldType = "double"
+		case fieldKindDecimal:
+			precision := field.Precision
+			if precision <= 0 {
+				precision = avroDefaultPrecision
+			}
+			fieldType = map[string]interface{}{
+				"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": field.Scale}
+		case fieldKindDateTime:
+			fieldType = map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}
+		case fieldKindEnum:
+			e := fieldEnums[table.Name+"."+field.Name]
+			if e.IsNum {
+				fieldType = "long"
+				break
+			}
+			// 同一个枚举在record中只能定义一次，之后通过名称引用
+			if definedEnums[e.Name] {
+				fieldType = e.Name
+				break
+			}
+			definedEnums[e.Name] = true
+			symbols := make([]string, 0, len(e.Enum.Values))
+			for _, enumValue := range e.Enum.Values {
+				if !avroNameRE.MatchString(enumValue.Value) {
+					return "", fmt.Errorf("enum(%s) value(%s) is not a valid avro enum symbol", e.Name, enumValue.Value)
+				}
+				symbols = append(symbols, enumValue.Value)
+			}
+			enumType := map[string]interface{}{"type": "enum", "name": e.Name, "symbols": symbols}
+			if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+				enumType["doc"] = comment
+			}
+			fieldType = enumType
+		default:
+			// JSON字段按字符串存储
+			fieldType = "string"
+		}
+		avroField := map[string]interface{}{"name": field.Name, "type": fieldType}
+		if field.Nullable {
+			avroField["type"] = []interface{}{"null", fieldType}
+			avroField["default"] = nil
+		}
+		if comment := genComment(field.CName, field.Explain); comment != "" {
+			avroField["doc"] = comment
+		}
+		fields = append(fields, avroField)
+	}
+	schema := map[string]interface{}{
+		"type":   "record",
+		"name":   strcase.ToCamel(table.Name),
+		"fields": fields,
+	}
+	if namespace := param.getNamespace(table.Name); namespace != "" {
+		schema["namespace"] = namespace
+	}
+	if table.CName != "" {
+		schema["doc"] = table.CName
+	}
+	body, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal avro schema of table(%s) fail", table.Name)
+	}
+	return string(body), nil
+}
+
+// CheckAvroCompatibility 按mode检查两个版本的avro schema是否兼容，返回所有不兼容的原因，为空表示兼容
+func CheckAvroCompatibility(oldSchema, newSchema, mode string) ([]string, error) {
+	var oldNode, newNode interface{}
+	if err := json.Unmarshal([]byte(oldSchema), &oldNode); err != nil {
+		return nil, errors.Wrapf(err, "parse old schema fail")
+	}
+	if err := json.Unmarshal([]byte(newSchema), &newNode); err != nil {
+		return nil, errors.Wrapf(err, "parse new schema fail")
+	}
+	var problems []string
+	switch mode {
+	case AvroCompatibilityBackward:
+		problems = newAvroResolver(newNode, oldNode).check("", newNode, oldNode)
+	case AvroCompatibilityForward:
+		problems = newAvroResolver(oldNode, newNode).check("", oldNode, newNode)
+	case AvroCompatibilityFull:
+		problems = append(newAvroResolver(newNode, oldNode).check("", newNode, oldNode),
+			newAvroResolver(oldNode, newNode).check("", oldNode, newNode)...)
+	default:
+		return nil, fmt.Errorf("unknown avro compatibility mode(%s)", mode)
+	}
+	return problems, nil
+}
+
+// avroResolver 按avro的schema resolution规则检查reader schema能否读取writer schema写入的数据
+type avroResolver struct {
+	readerNames map[string]interface{}
+	writerNames map[string]interface{}
+}
+
+func newAvroResolver(reader, writer interface{}) *avroResolver {
+	r := &avroResolver{
+		readerNames: make(map[string]interface{}),
+		writerNames: make(map[string]interface{}),
+	}
+	collectAvroNames(reader, "", r.readerNames)
+	collectAvroNames(writer, "", r.writerNames)
+	return r
+}
+
+// collectAvroNames 收集schema中定义的具名类型，包含不带命名空间的短名称
+func collectAvroNames(node interface{}, namespace string, names map[string]interface{}) {
+	switch n := node.(type) {
+	case []interface{}:
+		for _, item := range n {
+			collectAvroNames(item, namespace, names)
+		}
+	case map[string]interface{}:
+		if ns, ok := n["namespace"].(string); ok {
+			namespace = ns
+		}
+		if name, ok := n["name"].(string); ok {
+			switch n["type"] {
+			case "record", "enum", "fixed":
+				names[name] = n
+				if namespace != "" && !strings.Contains(name, ".") {
+					names[namespace+"."+name] = n
+				}
+			}
+		}
+		if fields, ok := n["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				if f, ok := field.(map[string]interface{}); ok {
+					collectAvroNames(f["type"], namespace, names)
+				}
+			}
+		}
+		if items, ok := n["items"]; ok {
+			collectAvroNames(items, namespace, names)
+		}
+		if values, ok := n["values"]; ok {
+			collectAvroNames(values, namespace, names)
+		}
+		if tp, ok := n["type"].([]interface{}); ok {
+			collectAvroNames(tp, namespace, names)
+		}
+		if tp, ok := n["type"].(map[string]interface{}); ok {
+			collectAvroNames(tp, namespace, names)
+		}
+	}
+}
+
+// resolve 将名称引用解析为定义，并将{"type": "long"}这类包装展开
+func (r *avroResolver) resolve(node interface{}, names map[string]interface{}) interface{} {
+	for i := 0; i < 8; i++ {
+		switch n := node.(type) {
+		case string:
+			if def, ok := names[n]; ok {
+				return def
+			}
+			return n
+		case map[string]interface{}:
+			tp, ok := n["type"]
+			if !ok {
+				return n
+			}
+			if s, ok := tp.(string); ok {
+				switch s {
+				case "record", "enum", "fixed", "array", "map":
+					return n
+				}
+				if _, logical := n["logicalType"]; logical {
+					return n
+				}
+			}
+			node = tp
+		default:
+			return node
+		}
+	}
+	return node
+}
+
+// avroTypeName 获取schema节点的类型名
+func avroTypeName(node interface{}) string {
+	switch n := node.(type) {
+	case string:
+		return n
+	case []interface{}:
+		return "union"
+	case map[string]interface{}:
+		if tp, ok := n["type"].(string); ok {
+			return tp
+		}
+	}
+	return ""
+}
+
+// avroPromotable writer类型能否提升为reader类型
+var avroPromotable = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+func (r *avroResolver) check(path string, reader, writer interface{}) []string {
+	reader = r.resolve(reader, r.readerNames)
+	writer = r.resolve(writer, r.writerNames)
+	if path == "" {
+		path = "$"
+	}
+	// writer为union时，其中每个分支都需要能被reader读取
+	if writerUnion, ok := writer.([]interface{}); ok {
+		var problems []string
+		for _, branch := range writerUnion {
+			problems = append(problems, r.check(path, reader, branch)...)
+		}
+		return problems
+	}
+	// reader为union时，writer需要能被其中任意一个分支读取
+	if readerUnion, ok := reader.([]interface{}); ok {
+		for _, branch := range readerUnion {
+			if len(r.check(path, branch, writer)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: type %s cannot be read as any branch of the union", path, avroTypeName(writer))}
+	}
+	readerType, writerType := avroTypeName(reader), avroTypeName(writer)
+	if readerType != writerType {
+		for _, promoted := range avroPromotable[writerType] {
+			if promoted == readerType {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: type %s cannot be read as %s", path, writerType, readerType)}
+	}
+	readerMap, _ := reader.(map[string]interface{})
+	writerMap, _ := writer.(map[string]interface{})
+	if readerMap == nil || writerMap == nil {
+		return nil
+	}
+	if readerMap["logicalType"] != writerMap["logicalType"] {
+		return []string{fmt.Sprintf("%s: logical type %v cannot be read as %v", path, writerMap["logicalType"], readerMap["logicalType"])}
+	}
+	if readerMap["logicalType"] == "decimal" &&
+		(fmt.Sprint(readerMap["precision"]) != fmt.Sprint(writerMap["precision"]) ||
+			fmt.Sprint(readerMap["scale"]) != fmt.Sprint(writerMap["scale"])) {
+		return []string{fmt.Sprintf("%s: decimal(%v,%v) cannot be read as decimal(%v,%v)", path,
+			writerMap["precision"], writerMap["scale"], readerMap["precision"], readerMap["scale"])}
+	}
+	switch readerType {
+	case "record":
+		return r.checkRecord(path, readerMap, writerMap)
+	case "enum":
+		return checkAvroEnum(path, readerMap, writerMap)
+	case "array":
+		return r.check(path+"[]", readerMap["items"], writerMap["items"])
+	case "map":
+		return r.check(path+"{}", readerMap["values"], writerMap["values"])
+	case "fixed":
+		if fmt.Sprint(readerMap["size"]) != fmt.Sprint(writerMap["size"]) {
+			return []string{fmt.Sprintf("%s: fixed size %v cannot be read as %v", path, writerMap["size"], readerMap["size"])}
+		}
+	}
+	return nil
+}
+
+// checkRecord reader中新增的字段需要有默认值，writer中多出的字段会被忽略
+func (r *avroResolver) checkRecord(path string, reader, writer map[string]interface{}) []string {
+	writerFields := make(map[string]map[string]interface{})
+	for _, field := range avroFields(writer) {
+		writerFields[field["name"].(string)] = field
+		for _, alias := range avroAliases(field) {
+			writerFields[alias] = field
+		}
+	}
+	var problems []string
+	for _, readerField := range avroFields(reader) {
+		name := readerField["name"].(string)
+		fieldPath := path + "." + name
+		writerField, ok := writerFields[name]
+		if !ok {
+			for _, alias := range avroAliases(readerField) {
+				if writerField, ok = writerFields[alias]; ok {
+					break
+				}
+			}
+		}
+		if !ok {
+			if _, hasDefault := readerField["default"]; !hasDefault {
+				problems = append(problems, fmt.Sprintf("%s: field is missing in writer and has no default", fieldPath))
+			}
+			continue
+		}
+		problems = append(problems, r.check(fieldPath, readerField["type"], writerField["type"])...)
+	}
+	return problems
+}
+
+func avroFields(record map[string]interface{}) []map[string]interface{} {
+	fields, _ := record["fields"].([]interface{})
+	ret := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		if f, ok := field.(map[string]interface{}); ok {
+			if _, ok := f["name"].(string); ok {
+				ret = append(ret, f)
+			}
+		}
+	}
+	return ret
+}
+
+func avroAliases(node map[string]interface{}) []string {
+	aliases, _ := node["aliases"].([]interface{})
+	ret := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		if s, ok := alias.(string); ok {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// checkAvroEnum writer的symbol需要都在reader中，否则reader需要有默认symbol
+func checkAvroEnum(path string, reader, writer map[string]interface{}) []string {
+	if _, hasDefault := reader["default"]; hasDefault {
+		return nil
+	}
+	readerSymbols := make(map[string]bool)
+	symbols, _ := reader["symbols"].([]interface{})
+	for _, symbol := range symbols {
+		readerSymbols[fmt.Sprint(symbol)] = true
+	}
+	var problems []string
+	writerSymbols, _ := writer["symbols"].([]interface{})
+	for _, symbol := range writerSymbols {
+		if !readerSymbols[fmt.Sprint(symbol)] {
+			problems = append(problems, fmt.Sprintf("%s: enum symbol %v is missing in reader", path, symbol))
+		}
+	}
+	return problems
+}
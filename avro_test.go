@@ -0,0 +1,137 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToAvroSchema(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	task := newGenTestTables()[0]
+	got, err := d.ToAvroSchema(ctx, task, &AvroParam{
+		Namespace:       "metacenter",
+		TableNamespaces: map[string]string{"t_task": "metacenter.task"},
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToAvroSchema() error = %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &schema); err != nil {
+		t.Fatalf("unmarshal avro schema fail: %v", err)
+	}
+	if schema["namespace"] != "metacenter.task" || schema["name"] != "TTask" {
+		t.Errorf("DefaultMetaCenter.ToAvroSchema() namespace/name = %v/%v", schema["namespace"], schema["name"])
+	}
+	types := make(map[string]interface{})
+	for _, field := range schema["fields"].([]interface{}) {
+		f := field.(map[string]interface{})
+		types[f["name"].(string)] = f["type"]
+	}
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{"id", "long"},
+		{"task_status", "long"},
+		{"phase", []interface{}{"null", map[string]interface{}{
+			"type": "enum", "name": "Phase", "doc": "任务阶段", "symbols": []interface{}{"parse_file", "send_file"}}}},
+		{"ext", []interface{}{"null", "string"}},
+		{"create_time", map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(types[tt.name], tt.want) {
+			t.Errorf("field(%s) type = %v, want %v", tt.name, types[tt.name], tt.want)
+		}
+	}
+	if problems, err := CheckAvroCompatibility(got, got, AvroCompatibilityFull); err != nil || len(problems) != 0 {
+		t.Errorf("CheckAvroCompatibility() of same schema = %v, %v", problems, err)
+	}
+}
+
+func TestCheckAvroCompatibility(t *testing.T) {
+	const base = `{"type":"record","name":"T","fields":[
+		{"name":"id","type":"long"},
+		{"name":"phase","type":{"type":"enum","name":"Phase","symbols":["a","b"]}},
+		{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}}]}`
+	tests := []struct {
+		name      string
+		newSchema string
+		mode      string
+		wantCnt   int
+		wantErr   bool
+	}{
+		{
+			"add nullable field is full compatible",
+			`{"type":"record","name":"T","fields":[
+				{"name":"id","type":"long"},
+				{"name":"phase","type":{"type":"enum","name":"Phase","symbols":["a","b"]}},
+				{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}},
+				{"name":"memo","type":["null","string"],"default":null}]}`,
+			AvroCompatibilityFull,
+			0,
+			false,
+		},
+		{
+			"add field without default breaks backward",
+			`{"type":"record","name":"T","fields":[
+				{"name":"id","type":"long"},
+				{"name":"phase","type":"string"},
+				{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}},
+				{"name":"memo","type":"string"}]}`,
+			AvroCompatibilityBackward,
+			2,
+			false,
+		},
+		{
+			"add enum symbol breaks forward",
+			`{"type":"record","name":"T","fields":[
+				{"name":"id","type":"long"},
+				{"name":"phase","type":{"type":"enum","name":"Phase","symbols":["a","b","c"]}},
+				{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}}]}`,
+			AvroCompatibilityForward,
+			1,
+			false,
+		},
+		{
+			"change decimal scale",
+			`{"type":"record","name":"T","fields":[
+				{"name":"id","type":"long"},
+				{"name":"phase","type":{"type":"enum","name":"Phase","symbols":["a","b"]}},
+				{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":3}}]}`,
+			AvroCompatibilityBackward,
+			1,
+			false,
+		},
+		{
+			"narrow long to int",
+			`{"type":"record","name":"T","fields":[
+				{"name":"id","type":"int"},
+				{"name":"phase","type":{"type":"enum","name":"Phase","symbols":["a","b"]}},
+				{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}}]}`,
+			AvroCompatibilityBackward,
+			1,
+			false,
+		},
+		{
+			"unknown mode",
+			base,
+			"NONE",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckAvroCompatibility(base, tt.newSchema, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckAvroCompatibility() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.wantCnt {
+				t.Errorf("CheckAvroCompatibility() = %v, want %d problems", got, tt.wantCnt)
+			}
+		})
+	}
+}
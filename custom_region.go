@@ -0,0 +1,128 @@
+package metacenter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 自定义区域标记，如:
+//
+//	// metacenter:begin custom
+//	func (t *TTask) IsDone() bool { ... }
+//	// metacenter:end custom
+//
+// 重新生成时区域内的内容从已有文件中保留，begin/end后为区域名，同一个文件中区域名不能重复
+var (
+	customRegionBeginRE = regexp.MustCompile(`^\s*(?://|#|--)\s*metacenter:begin\s+(\S+)\s*$`)
+	customRegionEndRE   = regexp.MustCompile(`^\s*(?://|#|--)\s*metacenter:end\s+(\S+)\s*$`)
+)
+
+// customRegion 自定义区域，begin/end为标记所在行的下标
+type customRegion struct {
+	name  string
+	begin int
+	end   int
+}
+
+// parseCustomRegions 解析自定义区域，标记不成对或区域名重复时返回错误
+func parseCustomRegions(lines []string) ([]*customRegion, error) {
+	var regions []*customRegion
+	names := make(map[string]bool)
+	var current *customRegion
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		if match := customRegionBeginRE.FindStringSubmatch(line); match != nil {
+			if current != nil {
+				return nil, fmt.Errorf("line %d: custom region(%s) begins before region(%s) ends", i+1, match[1], current.name)
+			}
+			if names[match[1]] {
+				return nil, fmt.Errorf("line %d: duplicate custom region(%s)", i+1, match[1])
+			}
+			names[match[1]] = true
+			current = &customRegion{name: match[1], begin: i}
+			continue
+		}
+		if match := customRegionEndRE.FindStringSubmatch(line); match != nil {
+			if current == nil || current.name != match[1] {
+				return nil, fmt.Errorf("line %d: custom region(%s) ends without begin", i+1, match[1])
+			}
+			current.end = i
+			regions = append(regions, current)
+			current = nil
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("line %d: custom region(%s) is not closed", current.begin+1, current.name)
+	}
+	return regions, nil
+}
+
+// hasCustomRegion 内容中是否有自定义区域的开始标记
+func hasCustomRegion(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if customRegionBeginRE.MatchString(strings.TrimRight(line, "\r")) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCustomRegionContent 内容中是否有非空的自定义区域，标记不成对时按有内容处理，避免误删
+func hasCustomRegionContent(content []byte) bool {
+	lines := strings.SplitAfter(string(content), "\n")
+	regions, err := parseCustomRegions(lines)
+	if err != nil {
+		return true
+	}
+	for _, region := range regions {
+		if strings.TrimSpace(strings.Join(lines[region.begin+1:region.end], "")) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCustomRegions 将已有文件中自定义区域的内容带入新生成的内容
+// 已有文件中有内容的区域在新内容中不存在时，该区域的内容会丢失，通过warnings返回
+func mergeCustomRegions(oldContent, newContent []byte) ([]byte, []string, error) {
+	oldLines := strings.SplitAfter(string(oldContent), "\n")
+	oldRegions, err := parseCustomRegions(oldLines)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse custom regions of existing file fail: %w", err)
+	}
+	newLines := strings.SplitAfter(string(newContent), "\n")
+	newRegions, err := parseCustomRegions(newLines)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse custom regions of generated content fail: %w", err)
+	}
+	if len(oldRegions) == 0 {
+		return newContent, nil, nil
+	}
+	bodies := make(map[string][]string, len(oldRegions))
+	for _, region := range oldRegions {
+		bodies[region.name] = oldLines[region.begin+1 : region.end]
+	}
+	var merged strings.Builder
+	last := 0
+	for _, region := range newRegions {
+		body, ok := bodies[region.name]
+		if !ok {
+			continue
+		}
+		delete(bodies, region.name)
+		merged.WriteString(strings.Join(newLines[last:region.begin+1], ""))
+		merged.WriteString(strings.Join(body, ""))
+		last = region.end
+	}
+	merged.WriteString(strings.Join(newLines[last:], ""))
+	var warnings []string
+	for _, region := range oldRegions {
+		body, ok := bodies[region.name]
+		if ok && strings.TrimSpace(strings.Join(body, "")) != "" {
+			warnings = append(warnings, fmt.Sprintf("custom region(%s) no longer exists in generated content, "+
+				"its content will be dropped", region.name))
+		}
+	}
+	return []byte(merged.String()), warnings, nil
+}
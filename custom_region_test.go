@@ -0,0 +1,187 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_mergeCustomRegions(t *testing.T) {
+	tests := []struct {
+		name         string
+		old          string
+		new          string
+		want         string
+		wantWarnings int
+		wantErr      bool
+	}{
+		{
+			"keep region content",
+			"a\n// metacenter:begin custom\nkeep1\nkeep2\n// metacenter:end custom\nb\n",
+			"A\n// metacenter:begin custom\n// metacenter:end custom\nB\n",
+			"A\n// metacenter:begin custom\nkeep1\nkeep2\n// metacenter:end custom\nB\n",
+			0,
+			false,
+		},
+		{
+			"multiple named regions",
+			"# metacenter:begin imports\nimport os\n# metacenter:end imports\n# metacenter:begin custom\nx = 1\n# metacenter:end custom\n",
+			"# metacenter:begin imports\n# metacenter:end imports\nclass A: pass\n# metacenter:begin custom\n# metacenter:end custom\n",
+			"# metacenter:begin imports\nimport os\n# metacenter:end imports\nclass A: pass\n# metacenter:begin custom\nx = 1\n# metacenter:end custom\n",
+			0,
+			false,
+		},
+		{
+			"anchor disappears",
+			"// metacenter:begin custom\nkeep\n// metacenter:end custom\n// metacenter:begin empty\n// metacenter:end empty\n",
+			"new\n",
+			"new\n",
+			1,
+			false,
+		},
+		{
+			"old file without regions",
+			"old\n",
+			"// metacenter:begin custom\n// metacenter:end custom\n",
+			"// metacenter:begin custom\n// metacenter:end custom\n",
+			0,
+			false,
+		},
+		{
+			"unclosed region in old file",
+			"// metacenter:begin custom\nkeep\n",
+			"new\n",
+			"",
+			0,
+			true,
+		},
+		{
+			"duplicate region in new content",
+			"old\n",
+			"// metacenter:begin custom\n// metacenter:end custom\n// metacenter:begin custom\n// metacenter:end custom\n",
+			"",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := mergeCustomRegions([]byte(tt.old), []byte(tt.new))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeCustomRegions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("mergeCustomRegions() = %q, want %q", got, tt.want)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("mergeCustomRegions() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_CustomRegion(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewGolangDataTypeGetter()))
+	tables := newGenTestTables()[:1]
+	params := []*GenerateGoFilesParam{{Name: "model", OutputDirPath: "model"}}
+	fsys := NewMemoryGenFS(nil)
+	fileSet, err := d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	if err := fileSet.WithFS(fsys).Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	// 在自定义区域中添加使用fmt的方法
+	const custom = "// String 自定义方法\nfunc (t *TTask) String() string {\n\treturn fmt.Sprint(t.Id)\n}\n"
+	content := string(fsys.Files()["model/t_task_model.go"])
+	content = strings.Replace(content, "// metacenter:begin custom\n", "// metacenter:begin custom\n"+custom, 1)
+	_ = fsys.WriteFile("model/t_task_model.go", []byte(content))
+
+	var warnings []string
+	fileSet, err = d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	fileSet.WithFS(fsys).WithWarningHandler(func(warning string) {
+		warnings = append(warnings, warning)
+	})
+	if err := fileSet.Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	if err := fileSet.Check(); err != nil {
+		t.Errorf("GeneratedFileSet.Check() with custom region error = %v", err)
+	}
+	got := string(fsys.Files()["model/t_task_model.go"])
+	if !strings.Contains(got, custom) || !strings.Contains(got, "\t\"fmt\"\n") {
+		t.Errorf("custom region should be kept with its imports, got %s", got)
+	}
+
+	// 模板中删除自定义区域后给出警告
+	params[0].TplName = "model_no_region"
+	params[0].TplFS = fstest.MapFS{"model_no_region.tpl": &fstest.MapFile{Data: []byte("package model\n")}}
+	fileSet, err = d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	out := bytes.NewBuffer(nil)
+	if err := fileSet.WithFS(fsys).DryRun(out); err != nil {
+		t.Fatalf("GeneratedFileSet.DryRun() error = %v", err)
+	}
+	want := "update    model/t_task_model.go\nwarning   model/t_task_model.go: custom region(custom) no longer exists " +
+		"in generated content, its content will be dropped\n"
+	if out.String() != want {
+		t.Errorf("GeneratedFileSet.DryRun() = %v, want %v", out.String(), want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("GeneratedFileSet.Write() unexpected warnings %v", warnings)
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_CustomRegionImports(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewGolangDataTypeGetter()))
+	tables := newGenTestTables()[:1]
+	params := []*GenerateGoFilesParam{{Name: "model", OutputDirPath: "model"}}
+	fsys := NewMemoryGenFS(nil)
+	fileSet, err := d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	if err := fileSet.WithFS(fsys).Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	// 自定义区域使用路径未知的包以及带别名的包，import由用户手动添加到已有文件中
+	const custom = "func (t *TTask) Valid() bool {\n\treturn validator.Check(t) && str.HasPrefix(\"a\", \"b\")\n}\n"
+	content := string(fsys.Files()["model/t_task_model.go"])
+	content = strings.Replace(content, "package model\n", "package model\n\nimport (\n\tstr \"strings\"\n\n"+
+		"\t\"github.com/xxx/validator\"\n\t\"github.com/xxx/unused\"\n)\n", 1)
+	content = strings.Replace(content, "// metacenter:begin custom\n", "// metacenter:begin custom\n"+custom, 1)
+	_ = fsys.WriteFile("model/t_task_model.go", []byte(content))
+
+	// 重新生成两次，第二次的结果应与第一次一致
+	for i := 0; i < 2; i++ {
+		fileSet, err = d.GenerateFiles(ctx, tables, params)
+		if err != nil {
+			t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+		}
+		if err := fileSet.WithFS(fsys).Write(); err != nil {
+			t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+		}
+	}
+	if err := fileSet.Check(); err != nil {
+		t.Errorf("GeneratedFileSet.Check() error = %v", err)
+	}
+	got := string(fsys.Files()["model/t_task_model.go"])
+	for _, want := range []string{custom, "\tstr \"strings\"\n", "\t\"github.com/xxx/validator\"\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("regenerated file should contain %q, got %s", want, got)
+		}
+	}
+	if strings.Contains(got, "github.com/xxx/unused") {
+		t.Errorf("regenerated file should not keep unused import, got %s", got)
+	}
+}
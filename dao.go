@@ -0,0 +1,40 @@
+package metacenter
+
+// DAOParam 生成DAO可指定的参数
+type DAOParam struct {
+	// OutputDirPath DAO输出文件夹路径
+	OutputDirPath string
+	// ModelPkg 模型包的导入路径，为空时表示模型与DAO同包
+	ModelPkg string
+	// EnumPkg 共享枚举包的导入路径，需与生成模型时的EnumPkg一致
+	EnumPkg string
+	// TypeConverter 字段类型获取器，需与生成模型时的TypeConverter一致，可为空字段是否使用指针类型取决于字段类型
+	TypeConverter DataTypeGetter
+	// WithTest 是否生成DAO的单元测试
+	WithTest bool
+}
+
+// NewDAOGenerateParams 生成DAO所需的模板参数：dao_base(schema级，DB、Dialect以及SQL拼接函数)、
+// dao(表级，增删改查以及唯一字段查询)，WithTest时额外生成dao_mock_test(schema级，sqlmock风格的数据库替身)
+// 以及dao_test(表级，使用替身的单元测试)
+func NewDAOGenerateParams(param *DAOParam) []*GenerateGoFilesParam {
+	names := []string{"dao_base", "dao"}
+	if param.WithTest {
+		names = append(names, "dao_mock_test", "dao_test")
+	}
+	params := make([]*GenerateGoFilesParam, 0, len(names))
+	for _, name := range names {
+		genParam := &GenerateGoFilesParam{
+			Name:          name,
+			OutputDirPath: param.OutputDirPath,
+			ModelPkg:      param.ModelPkg,
+			EnumPkg:       param.EnumPkg,
+			TypeConverter: param.TypeConverter,
+		}
+		if name == "dao_base" || name == "dao_mock_test" {
+			genParam.Scope = GenerateScopeSchema
+		}
+		params = append(params, genParam)
+	}
+	return params
+}
@@ -0,0 +1,127 @@
+package metacenter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewDAOGenerateParams(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tables := newGenTestTables()
+	tables[1].TableFields[7] = &TableField{TableID: 2, FieldID: 7, IsUnique: 1}
+	params := NewDAOGenerateParams(&DAOParam{
+		OutputDirPath: "dao",
+		ModelPkg:      "github.com/xxx/model",
+		EnumPkg:       "github.com/xxx/model/enums",
+		WithTest:      true,
+	})
+	fileSet, err := d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	var paths []string
+	for _, file := range fileSet.Files() {
+		paths = append(paths, file.Path)
+	}
+	wantPaths := "dao/dao_base.go,dao/t_task_dao.go,dao/t_sub_task_dao.go,dao/dao_mock_test.go," +
+		"dao/t_task_dao_test.go,dao/t_sub_task_dao_test.go"
+	if strings.Join(paths, ",") != wantPaths {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() paths = %v, want %v", paths, wantPaths)
+	}
+	tests := []struct {
+		path     string
+		contains []string
+	}{
+		{
+			"dao/dao_base.go",
+			[]string{
+				"type Dialect interface {",
+				"func (MySQLDialect) Placeholder(int) string {",
+				"\treturn \"RETURNING \" + d.Quote(column)\n",
+			},
+		},
+		{
+			"dao/t_task_dao.go",
+			[]string{
+				"\t\"github.com/xxx/model\"\n",
+				"var tTaskColumnsInsert = []string{\"task_status\", \"phase\", \"ext\", \"create_time\"}\n",
+				"func (dao *TTaskDAO) Insert(ctx context.Context, m *model.TTask) error {",
+				"\tm.Id = uint(id)\n",
+				"func (dao *TTaskDAO) BatchInsert(ctx context.Context, ms []*model.TTask) (int64, error) {",
+				"func (dao *TTaskDAO) Update(ctx context.Context, m *model.TTask) (int64, error) {",
+				"func (dao *TTaskDAO) Delete(ctx context.Context, id uint) (int64, error) {",
+				"func (dao *TTaskDAO) GetByPK(ctx context.Context, id uint) (*model.TTask, error) {",
+			},
+		},
+		{
+			"dao/t_sub_task_dao.go",
+			[]string{"func (dao *TSubTaskDAO) FindByScore(ctx context.Context, score float64) (*model.TSubTask, error) {"},
+		},
+		{
+			"dao/t_task_dao_test.go",
+			[]string{
+				"\t\tTaskStatus: enums.TaskStatus(1),\n",
+				"\tmock.ExpectExec(\"INSERT INTO `t_task` (`task_status`, `phase`, `ext`, `create_time`) VALUES (?, ?, ?, ?)\",",
+				"VALUES ($1, $2, $3, $4) RETURNING \"id\"`,",
+			},
+		},
+		{
+			"dao/t_sub_task_dao_test.go",
+			[]string{"func TestTSubTaskDAO_FindByScore(t *testing.T) {"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			content := string(fileSet.Get(tt.path).Content)
+			for _, want := range tt.contains {
+				if !strings.Contains(content, want) {
+					t.Errorf("file(%s) should contain %q, got %s", tt.path, want, content)
+				}
+			}
+		})
+	}
+	if strings.Contains(string(fileSet.Get("dao/t_task_dao.go").Content), "FindBy") {
+		t.Errorf("t_task has no unique field, FindBy should not be generated")
+	}
+}
+
+func TestNewDAOGenerateParams_Run(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tables := newGenTestTables()
+	tables[1].TableFields[7] = &TableField{TableID: 2, FieldID: 7, IsUnique: 1}
+	params := []*GenerateGoFilesParam{
+		{Name: "enum", Scope: GenerateScopeSchema, OutputDirPath: "enums"},
+		{Name: "model", OutputDirPath: "model", EnumPkg: "example.com/gen/enums", TypeConverter: NewGolangDataTypeGetter()},
+	}
+	params = append(params, NewDAOGenerateParams(&DAOParam{
+		OutputDirPath: "dao",
+		ModelPkg:      "example.com/gen/model",
+		EnumPkg:       "example.com/gen/enums",
+		TypeConverter: NewGolangDataTypeGetter(),
+		WithTest:      true,
+	})...)
+	fileSet, err := d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	model := string(fileSet.Get("model/t_task_model.go").Content)
+	for _, want := range []string{"\tPhase enums.Phase `", "\tExt *string `", "\tCreateTime utils.DateTime `"} {
+		if !strings.Contains(model, want) {
+			t.Errorf("model should contain %q, got %s", want, model)
+		}
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get working dir fail: %v", err)
+	}
+	// 生成的模型使用本模块的utils.DateTime，通过replace指向当前代码
+	runGeneratedGoTests(t, fileSet, map[string]string{
+		"go.mod": "module example.com/gen\n\ngo 1.17\n\nrequire github.com/imqishi/metacenter v0.0.0\n\n" +
+			"replace github.com/imqishi/metacenter => " + filepath.ToSlash(root) + "\n",
+	})
+}
@@ -0,0 +1,282 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DataDictFormatMarkdown Markdown格式
+	DataDictFormatMarkdown = "markdown"
+	// DataDictFormatHTML 自包含的HTML格式
+	DataDictFormatHTML = "html"
+	// DataDictFormatCSV 带UTF-8 BOM以及CRLF换行的CSV格式，可直接用Excel打开
+	DataDictFormatCSV = "csv"
+)
+
+// utf8BOM Excel依赖BOM识别UTF-8编码的CSV
+const utf8BOM = "\ufeff"
+
+// DataDictParam 生成数据字典可指定的参数
+type DataDictParam struct {
+	// Format 输出格式，见DataDictFormatXXX，默认为Markdown
+	Format string
+	// Title 文档标题，默认为"数据字典"
+	Title string
+}
+
+// dataDictTable 数据字典中的表
+type dataDictTable struct {
+	Name   string
+	CName  string
+	Fields []*dataDictField
+}
+
+// dataDictField 数据字典中的字段
+type dataDictField struct {
+	Name     string
+	CName    string
+	Type     string
+	Explain  string
+	IsPK     bool
+	AutoIncr bool
+	IsUnique bool
+	Encrypt  bool
+	Nullable bool
+	Enum     *dataDictEnum
+}
+
+// dataDictEnum 数据字典中字段使用的枚举
+type dataDictEnum struct {
+	CName   string
+	Explain string
+	Values  []*EnumValue
+}
+
+// Flags 字段标记，如主键、自增、唯一、加密、可为空
+func (f *dataDictField) Flags() []string {
+	var flags []string
+	if f.IsPK {
+		flags = append(flags, "主键")
+	}
+	if f.AutoIncr {
+		flags = append(flags, "自增")
+	}
+	if f.IsUnique {
+		flags = append(flags, "唯一")
+	}
+	if f.Encrypt {
+		flags = append(flags, "加密")
+	}
+	if f.Nullable {
+		flags = append(flags, "可为空")
+	}
+	return flags
+}
+
+// EnumSummary 枚举值的单行描述，如1=待执行; 2=已完成
+func (f *dataDictField) EnumSummary() string {
+	if f.Enum == nil {
+		return ""
+	}
+	values := make([]string, 0, len(f.Enum.Values))
+	for _, enumValue := range f.Enum.Values {
+		value := enumValue.Value + "=" + enumValue.Desc
+		if enumValue.IsOffline() {
+			value += "(已下线)"
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "; ")
+}
+
+// ToDataDictionary 将表导出为数据字典，包含目录、字段列表以及字段使用的完整枚举值
+// 通常传入GetAllTables的结果
+func (d *DefaultMetaCenter) ToDataDictionary(ctx context.Context, tables []*Table, param *DataDictParam) (string, error) {
+	if param == nil {
+		param = &DataDictParam{}
+	}
+	title := param.Title
+	if title == "" {
+		title = "数据字典"
+	}
+	dictTables := make([]*dataDictTable, 0, len(tables))
+	for _, table := range tables {
+		dictTables = append(dictTables, d.getDataDictTable(ctx, table))
+	}
+	switch param.Format {
+	case "", DataDictFormatMarkdown:
+		return dataDictMarkdown(title, dictTables), nil
+	case DataDictFormatHTML:
+		return dataDictHTML(title, dictTables)
+	case DataDictFormatCSV:
+		return dataDictCSV(dictTables)
+	}
+	return "", fmt.Errorf("unknown data dictionary format(%s)", param.Format)
+}
+
+func (d *DefaultMetaCenter) getDataDictTable(ctx context.Context, table *Table) *dataDictTable {
+	dictTable := &dataDictTable{Name: table.Name, CName: table.CName}
+	for _, field := range table.Fields {
+		dictField := &dataDictField{
+			Name:     field.Name,
+			CName:    field.CName,
+			Explain:  field.Explain,
+			IsPK:     field.IsPK,
+			AutoIncr: field.AutoIncr,
+			Nullable: field.Nullable,
+		}
+		if dataType := d.dataTypeGetter.GetByID(ctx, field.Type); dataType != nil {
+			dictField.Type = dataType.Name
+			if dataType.CName != "" && dataType.CName != dataType.Name {
+				dictField.Type = fmt.Sprintf("%s(%s)", dataType.CName, dataType.Name)
+			}
+		}
+		if tableField := table.GetTableField(field.ID); tableField != nil {
+			dictField.IsPK = dictField.IsPK || tableField.IsPrimaryKey == 1
+			dictField.IsUnique = tableField.IsUnique == 1
+			dictField.Encrypt = tableField.IsEncrypt == 1
+		}
+		if d.getFieldKind(ctx, field) == fieldKindEnum {
+			dictField.Enum = &dataDictEnum{
+				CName:   field.Enum.CName,
+				Explain: field.Enum.Explain,
+				Values:  field.Enum.Values,
+			}
+		}
+		dictTable.Fields = append(dictTable.Fields, dictField)
+	}
+	return dictTable
+}
+
+// markdownCell 转义Markdown表格单元格中的竖线以及换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func dataDictMarkdown(title string, tables []*dataDictTable) string {
+	body := bytes.NewBuffer(nil)
+	fmt.Fprintf(body, "# %s\n\n## 目录\n\n", title)
+	for _, table := range tables {
+		fmt.Fprintf(body, "- [%s %s](#%s)\n", table.Name, markdownCell(table.CName), table.Name)
+	}
+	for _, table := range tables {
+		fmt.Fprintf(body, "\n<a id=\"%s\"></a>\n\n## %s %s\n\n", table.Name, table.Name, markdownCell(table.CName))
+		body.WriteString("| 字段 | 类型 | 中文名 | 说明 | 标记 |\n| --- | --- | --- | --- | --- |\n")
+		for _, field := range table.Fields {
+			fmt.Fprintf(body, "| %s | %s | %s | %s | %s |\n", field.Name, markdownCell(field.Type),
+				markdownCell(field.CName), markdownCell(field.Explain), strings.Join(field.Flags(), ", "))
+		}
+		for _, field := range table.Fields {
+			if field.Enum == nil {
+				continue
+			}
+			fmt.Fprintf(body, "\n### %s %s\n\n", field.Name, markdownCell(field.Enum.CName))
+			if field.Enum.Explain != "" {
+				fmt.Fprintf(body, "%s\n\n", markdownCell(field.Enum.Explain))
+			}
+			body.WriteString("| 值 | 英文名 | 描述 | 状态 | 说明 |\n| --- | --- | --- | --- | --- |\n")
+			for _, enumValue := range field.Enum.Values {
+				status := "正常"
+				if enumValue.IsOffline() {
+					status = "已下线"
+				}
+				fmt.Fprintf(body, "| %s | %s | %s | %s | %s |\n", markdownCell(enumValue.Value),
+					markdownCell(enumValue.EName), markdownCell(enumValue.Desc), status, markdownCell(enumValue.Explain))
+			}
+		}
+	}
+	return body.String()
+}
+
+var dataDictHTMLTpl = template.Must(template.New("data_dictionary").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;margin:2em;color:#222}
+table{border-collapse:collapse;margin:0.5em 0 1.5em}
+th,td{border:1px solid #ccc;padding:4px 8px;text-align:left;vertical-align:top}
+th{background:#f5f5f5}
+.flag{display:inline-block;margin-right:4px;padding:0 4px;border-radius:3px;background:#e8f0fe;font-size:0.85em}
+.offline{color:#999;text-decoration:line-through}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<h2>目录</h2>
+<ul>
+{{- range .Tables}}
+<li><a href="#{{.Name}}">{{.Name}} {{.CName}}</a></li>
+{{- end}}
+</ul>
+{{- range .Tables}}
+<h2 id="{{.Name}}">{{.Name}} {{.CName}}</h2>
+<table>
+<tr><th>字段</th><th>类型</th><th>中文名</th><th>说明</th><th>标记</th></tr>
+{{- range .Fields}}
+<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.CName}}</td><td>{{.Explain}}</td><td>{{range .Flags}}<span class="flag">{{.}}</span>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- range .Fields}}{{if .Enum}}
+<h3>{{.Name}} {{.Enum.CName}}</h3>
+{{- if .Enum.Explain}}
+<p>{{.Enum.Explain}}</p>
+{{- end}}
+<table>
+<tr><th>值</th><th>英文名</th><th>描述</th><th>状态</th><th>说明</th></tr>
+{{- range .Enum.Values}}
+<tr{{if .IsOffline}} class="offline"{{end}}><td>{{.Value}}</td><td>{{.EName}}</td><td>{{.Desc}}</td><td>{{if .IsOffline}}已下线{{else}}正常{{end}}</td><td>{{.Explain}}</td></tr>
+{{- end}}
+</table>
+{{- end}}{{end}}
+{{- end}}
+</body>
+</html>
+`))
+
+func dataDictHTML(title string, tables []*dataDictTable) (string, error) {
+	body := bytes.NewBuffer(nil)
+	err := dataDictHTMLTpl.Execute(body, map[string]interface{}{"Title": title, "Tables": tables})
+	if err != nil {
+		return "", errors.Wrapf(err, "execute data dictionary html template fail")
+	}
+	return body.String(), nil
+}
+
+// csvSafeCell 以=、+、-、@等开头的单元格会被Excel等当作公式执行，加上'前缀作为纯文本
+func csvSafeCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func dataDictCSV(tables []*dataDictTable) (string, error) {
+	body := bytes.NewBufferString(utf8BOM)
+	w := csv.NewWriter(body)
+	w.UseCRLF = true
+	records := [][]string{{"表名", "表中文名", "字段", "类型", "中文名", "说明", "标记", "枚举值"}}
+	for _, table := range tables {
+		for _, field := range table.Fields {
+			record := []string{table.Name, table.CName, field.Name, field.Type, field.CName,
+				field.Explain, strings.Join(field.Flags(), ","), field.EnumSummary()}
+			for i, cell := range record {
+				record[i] = csvSafeCell(cell)
+			}
+			records = append(records, record)
+		}
+	}
+	if err := w.WriteAll(records); err != nil {
+		return "", errors.Wrapf(err, "write data dictionary csv fail")
+	}
+	return body.String(), nil
+}
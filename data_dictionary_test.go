@@ -0,0 +1,146 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newDataDictTestTables() []*Table {
+	return []*Table{
+		{
+			ID:    3,
+			Name:  "t_user",
+			CName: "用户表",
+			Fields: []*Field{
+				{ID: 1, Name: "id", CName: "自增ID", Type: 2, IsPK: true, AutoIncr: true},
+				{ID: 8, Name: "phone", CName: "手机号", Type: 3, Explain: "格式a|b <脱敏>"},
+			},
+			TableFields: map[int]*TableField{
+				8: {TableID: 3, FieldID: 8, IsUnique: 1, IsEncrypt: 1},
+			},
+		},
+	}
+}
+
+func TestDefaultMetaCenter_ToDataDictionary(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name     string
+		tables   []*Table
+		param    *DataDictParam
+		contains []string
+		wantErr  bool
+	}{
+		{
+			"markdown",
+			newDataDictTestTables(),
+			nil,
+			[]string{
+				"# 数据字典\n\n## 目录\n\n- [t_user 用户表](#t_user)\n",
+				"| id | 非负整数(uint) | 自增ID |  | 主键, 自增 |\n",
+				"| phone | 字符串(string) | 手机号 | 格式a\\|b <脱敏> | 唯一, 加密 |\n",
+			},
+			false,
+		},
+		{
+			"markdown enum values",
+			newGenTestTables(),
+			&DataDictParam{Format: DataDictFormatMarkdown, Title: "任务"},
+			[]string{
+				"# 任务\n",
+				"### task_status 任务状态\n",
+				"| 3 | fail | 已失败 | 已下线 |  |\n",
+			},
+			false,
+		},
+		{
+			"html",
+			newDataDictTestTables(),
+			&DataDictParam{Format: DataDictFormatHTML},
+			[]string{
+				`<li><a href="#t_user">t_user 用户表</a></li>`,
+				`<td>格式a|b &lt;脱敏&gt;</td><td><span class="flag">唯一</span><span class="flag">加密</span></td>`,
+			},
+			false,
+		},
+		{
+			"html enum values",
+			newGenTestTables(),
+			&DataDictParam{Format: DataDictFormatHTML},
+			[]string{`<tr class="offline"><td>3</td><td>fail</td><td>已失败</td><td>已下线</td><td></td></tr>`},
+			false,
+		},
+		{
+			"unknown format",
+			newGenTestTables(),
+			&DataDictParam{Format: "xlsx"},
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.ToDataDictionary(ctx, tt.tables, tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultMetaCenter.ToDataDictionary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("DefaultMetaCenter.ToDataDictionary() = %v, want contains %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_ToDataDictionary_CSV(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	got, err := d.ToDataDictionary(ctx, newGenTestTables(), &DataDictParam{Format: DataDictFormatCSV})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToDataDictionary() error = %v", err)
+	}
+	if !strings.HasPrefix(got, utf8BOM) || !strings.Contains(got, "\r\n") {
+		t.Errorf("DefaultMetaCenter.ToDataDictionary() csv should start with BOM and use CRLF")
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(got, utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv fail: %v", err)
+	}
+	if len(records) != 10 {
+		t.Fatalf("csv records = %d, want 10", len(records))
+	}
+	want := []string{"t_task", "任务表", "task_status", "枚举(enum)", "任务状态", "", "",
+		"1=待执行; 2=已完成; 3=已失败(已下线)"}
+	if !reflect.DeepEqual(records[2], want) {
+		t.Errorf("csv record = %v, want %v", records[2], want)
+	}
+}
+
+func TestDefaultMetaCenter_ToDataDictionary_CSVInjection(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tables := newGenTestTables()[:1]
+	tables[0].CName = "=HYPERLINK(\"http://x\")"
+	tables[0].Fields[0].CName = "+1"
+	tables[0].Fields[0].Explain = "-2"
+	tables[0].Fields[1].Explain = "@SUM(A1)"
+	got, err := d.ToDataDictionary(ctx, tables, &DataDictParam{Format: DataDictFormatCSV})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToDataDictionary() error = %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(got, utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv fail: %v", err)
+	}
+	if got, want := records[1][1:6], []string{"'=HYPERLINK(\"http://x\")", "id", "非负整数(uint)", "'+1", "'-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("csv record = %v, want %v", got, want)
+	}
+	if got, want := records[2][5], "'@SUM(A1)"; got != want {
+		t.Errorf("csv cell = %v, want %v", got, want)
+	}
+}
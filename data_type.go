@@ -20,6 +20,8 @@ const (
 	DataTypeEnum = "enum"
 	// DataTypeJSON json对象类型
 	DataTypeJSON = "json"
+	// DataTypeDecimal 小数类型
+	DataTypeDecimal = "decimal"
 )
 
 // DataType 数据类型
@@ -91,6 +93,12 @@ var defaultDataType = []*DataType{
 		Name:  DataTypeJSON,
 		CName: "JSON对象/数组",
 	},
+	{
+		ID:    8,
+		Name:  DataTypeDecimal,
+		CName: "小数",
+		IsNum: true,
+	},
 }
 
 var defaultDataTypeMap map[string]*DataType
@@ -190,8 +198,14 @@ var golangDataType = []*DataType{
 
 var golangDataTypeMap map[string]*DataType
 
-// GetByName 根据变量类型名称获取类型配置
+// GetByName 根据变量类型名称获取类型配置，支持Go类型名、逻辑类型(DataTypeXXX)以及MySQL类型名
 func (d *GolangDataTypeGetter) GetByName(ctx context.Context, name string) *DataType {
+	// Go类型名以及与之同名的逻辑类型直接匹配，避免uint等按包含int匹配为int
+	for _, dataType := range golangDataType[1:] {
+		if dataType.Name == name {
+			return dataType
+		}
+	}
 	if strings.Contains(name, "int") {
 		if strings.Contains(name, "big") {
 			if strings.Contains(name, "unsigned") {
@@ -215,3 +229,51 @@ func (d *GolangDataTypeGetter) GetByName(ctx context.Context, name string) *Data
 	}
 	return golangDataType[6]
 }
+
+// TypeScriptDataTypeGetter TypeScript数据类型获取器，GetByName按字段逻辑类型(DataTypeXXX)获取对应的TypeScript类型
+type TypeScriptDataTypeGetter struct {
+}
+
+// NewTypeScriptDataTypeGetter 实例化TypeScript数据类型获取器
+func NewTypeScriptDataTypeGetter() *TypeScriptDataTypeGetter {
+	return &TypeScriptDataTypeGetter{}
+}
+
+var typeScriptDataType = []*DataType{
+	{},
+	{
+		ID:    1,
+		Name:  "number",
+		CName: "数字",
+		IsNum: true,
+	},
+	{
+		ID:    2,
+		Name:  "string",
+		CName: "字符串",
+	},
+	{
+		ID:    3,
+		Name:  "unknown",
+		CName: "JSON对象/数组",
+	},
+}
+
+// GetByID 根据id获取数据类型配置
+func (d *TypeScriptDataTypeGetter) GetByID(ctx context.Context, id int) *DataType {
+	if id >= len(typeScriptDataType) {
+		return typeScriptDataType[0]
+	}
+	return typeScriptDataType[id]
+}
+
+// GetByName 根据字段逻辑类型获取TypeScript类型，小数以及日期时间使用字符串避免精度以及时区问题
+func (d *TypeScriptDataTypeGetter) GetByName(ctx context.Context, name string) *DataType {
+	switch name {
+	case DataTypeInt, DataTypeUInt, DataTypeFloat:
+		return typeScriptDataType[1]
+	case DataTypeJSON:
+		return typeScriptDataType[3]
+	}
+	return typeScriptDataType[2]
+}
@@ -17,6 +17,19 @@ type Enum struct {
 	Value2Values map[string]*EnumValue `json:"-"`
 }
 
+// getValue 根据枚举值获取枚举值配置，不存在时返回nil
+func (e *Enum) getValue(value string) *EnumValue {
+	if e.Value2Values != nil {
+		return e.Value2Values[value]
+	}
+	for _, enumValue := range e.Values {
+		if enumValue.Value == value {
+			return enumValue
+		}
+	}
+	return nil
+}
+
 // EnumGetter 枚举获取接口
 type EnumGetter interface {
 	// GetByID 根据枚举ID获取枚举配置
@@ -2,6 +2,13 @@ package metacenter
 
 import "context"
 
+const (
+	// EnumValueStatusOnline 枚举值正常使用
+	EnumValueStatusOnline = 0
+	// EnumValueStatusOffline 枚举值已下线
+	EnumValueStatusOffline = 1
+)
+
 // EnumValue 枚举值
 type EnumValue struct {
 	// ID 唯一ID
@@ -14,12 +21,17 @@ type EnumValue struct {
 	Desc string `json:"desc"`
 	// Value 枚举值，如1/2/3，waiting/start/finish...
 	Value string `json:"value"`
-	// Status 枚举状态，用于下线部分枚举值
+	// Status 枚举状态，用于下线部分枚举值，见EnumValueStatusXXX
 	Status int `json:"status"`
 	// Explain 备注
 	Explain string `json:"explain"`
 }
 
+// IsOffline 枚举值是否已下线
+func (v *EnumValue) IsOffline() bool {
+	return v.Status == EnumValueStatusOffline
+}
+
 // EnumValueGetter 枚举值获取接口
 type EnumValueGetter interface {
 	// FindByEnumID 根据enum的id获取值列表
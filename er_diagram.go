@@ -0,0 +1,301 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+const (
+	// ERDiagramMermaid Mermaid erDiagram格式
+	ERDiagramMermaid = "mermaid"
+	// ERDiagramPlantUML PlantUML格式
+	ERDiagramPlantUML = "plantuml"
+	// ERDiagramDOT Graphviz DOT格式
+	ERDiagramDOT = "dot"
+)
+
+// ERDiagramParam 生成ER图可指定的参数
+type ERDiagramParam struct {
+	// Format 输出格式，见ERDiagramXXX，默认为Mermaid
+	Format string
+	// Tables 只输出指定英文名的表，为空时输出全部
+	Tables []string
+	// WithRefTables 指定Tables时，同时输出被这些表直接关联的表
+	WithRefTables bool
+	// KeysOnly 只输出主键、唯一键以及关联字段
+	KeysOnly bool
+}
+
+// erField ER图中的字段
+type erField struct {
+	Name     string
+	Type     string
+	Comment  string
+	IsPK     bool
+	IsUnique bool
+	IsFK     bool
+	Nullable bool
+}
+
+// erTable ER图中的表
+type erTable struct {
+	Name   string
+	CName  string
+	Fields []*erField
+}
+
+// erRelation ER图中的关联，From表的Field字段来自To表
+// 字段唯一时为一对一，否则为多对一；字段可为空时To表一侧为0或1
+type erRelation struct {
+	From     string
+	To       string
+	Field    string
+	IsUnique bool
+	Nullable bool
+}
+
+// ToERDiagram 根据TableField.RefTableID生成ER图，展示主键、关联字段以及关联的基数
+// 关联表不在tables中时忽略该关联
+func (d *DefaultMetaCenter) ToERDiagram(ctx context.Context, tables []*Table, param *ERDiagramParam) (string, error) {
+	if param == nil {
+		param = &ERDiagramParam{}
+	}
+	selected, err := selectERTables(tables, param)
+	if err != nil {
+		return "", err
+	}
+	idTables := make(map[int]*Table, len(tables))
+	for _, table := range tables {
+		idTables[table.ID] = table
+	}
+	selectedNames := make(map[string]bool, len(selected))
+	for _, table := range selected {
+		selectedNames[table.Name] = true
+	}
+	erTables := make([]*erTable, 0, len(selected))
+	var relations []*erRelation
+	for _, table := range selected {
+		t := &erTable{Name: table.Name, CName: table.CName}
+		for _, field := range table.Fields {
+			f := &erField{
+				Name:     field.Name,
+				Type:     d.getFieldKind(ctx, field),
+				Comment:  genComment(field.CName, ""),
+				IsPK:     field.IsPK,
+				Nullable: field.Nullable,
+			}
+			if tableField := table.GetTableField(field.ID); tableField != nil {
+				f.IsPK = f.IsPK || tableField.IsPrimaryKey == 1
+				f.IsUnique = tableField.IsUnique == 1
+				// 关联表未被筛选时仍标记为关联字段，但不输出关联
+				f.IsFK = tableField.RefTableID != 0
+				if refTable, ok := idTables[tableField.RefTableID]; ok && selectedNames[refTable.Name] {
+					relations = append(relations, &erRelation{
+						From:     table.Name,
+						To:       refTable.Name,
+						Field:    field.Name,
+						IsUnique: f.IsPK || f.IsUnique,
+						Nullable: field.Nullable,
+					})
+				}
+			}
+			if param.KeysOnly && !f.IsPK && !f.IsUnique && !f.IsFK {
+				continue
+			}
+			t.Fields = append(t.Fields, f)
+		}
+		erTables = append(erTables, t)
+	}
+	switch param.Format {
+	case "", ERDiagramMermaid:
+		return erMermaid(erTables, relations), nil
+	case ERDiagramPlantUML:
+		return erPlantUML(erTables, relations), nil
+	case ERDiagramDOT:
+		return erDOT(erTables, relations), nil
+	}
+	return "", fmt.Errorf("unknown er diagram format(%s)", param.Format)
+}
+
+// selectERTables 按参数筛选需要输出的表，保持tables中的顺序
+func selectERTables(tables []*Table, param *ERDiagramParam) ([]*Table, error) {
+	if len(param.Tables) == 0 {
+		return tables, nil
+	}
+	nameTables := make(map[string]*Table, len(tables))
+	idTables := make(map[int]*Table, len(tables))
+	for _, table := range tables {
+		nameTables[table.Name] = table
+		idTables[table.ID] = table
+	}
+	names := make(map[string]bool, len(param.Tables))
+	for _, name := range param.Tables {
+		table, ok := nameTables[name]
+		if !ok {
+			return nil, fmt.Errorf("table(%s) not found", name)
+		}
+		names[name] = true
+		if !param.WithRefTables {
+			continue
+		}
+		for _, tableField := range table.TableFields {
+			if refTable, ok := idTables[tableField.RefTableID]; ok {
+				names[refTable.Name] = true
+			}
+		}
+	}
+	selected := make([]*Table, 0, len(names))
+	for _, table := range tables {
+		if names[table.Name] {
+			selected = append(selected, table)
+		}
+	}
+	return selected, nil
+}
+
+// keys 字段的键标记
+func (f *erField) keys() []string {
+	var keys []string
+	if f.IsPK {
+		keys = append(keys, "PK")
+	}
+	if f.IsFK {
+		keys = append(keys, "FK")
+	}
+	if f.IsUnique && !f.IsPK {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+func erMermaid(tables []*erTable, relations []*erRelation) string {
+	body := bytes.NewBufferString("erDiagram\n")
+	for _, table := range tables {
+		fmt.Fprintf(body, "    %s {\n", table.Name)
+		for _, field := range table.Fields {
+			line := fmt.Sprintf("        %s %s", field.Type, field.Name)
+			if keys := field.keys(); len(keys) != 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			if field.Comment != "" {
+				// mermaid的注释中不能转义双引号
+				line += fmt.Sprintf(" \"%s\"", strings.ReplaceAll(field.Comment, "\"", "'"))
+			}
+			body.WriteString(line + "\n")
+		}
+		body.WriteString("    }\n")
+	}
+	for _, relation := range relations {
+		to, from := "||", "}o"
+		if relation.Nullable {
+			to = "o|"
+		}
+		if relation.IsUnique {
+			from = "|o"
+		}
+		fmt.Fprintf(body, "    %s %s--%s %s : \"%s\"\n", relation.From, from, to, relation.To, relation.Field)
+	}
+	return body.String()
+}
+
+func erPlantUML(tables []*erTable, relations []*erRelation) string {
+	body := bytes.NewBufferString("@startuml\nhide circle\nskinparam linetype ortho\n")
+	for _, table := range tables {
+		title := table.Name
+		if table.CName != "" {
+			title += "\\n" + table.CName
+		}
+		fmt.Fprintf(body, "\nentity \"%s\" as %s {\n", strings.ReplaceAll(title, "\"", "'"), table.Name)
+		// 主键在分隔线之上，其余字段在分隔线之下，*表示不可为空
+		var pks, others []string
+		for _, field := range table.Fields {
+			line := "  "
+			if !field.Nullable {
+				line += "* "
+			}
+			line += fmt.Sprintf("%s : %s", field.Name, field.Type)
+			for _, key := range field.keys() {
+				line += fmt.Sprintf(" <<%s>>", key)
+			}
+			if field.Comment != "" {
+				line += " // " + field.Comment
+			}
+			if field.IsPK {
+				pks = append(pks, line)
+			} else {
+				others = append(others, line)
+			}
+		}
+		for _, line := range pks {
+			body.WriteString(line + "\n")
+		}
+		body.WriteString("  --\n")
+		for _, line := range others {
+			body.WriteString(line + "\n")
+		}
+		body.WriteString("}\n")
+	}
+	if len(relations) != 0 {
+		body.WriteString("\n")
+	}
+	for _, relation := range relations {
+		from, to := "}o", "||"
+		if relation.IsUnique {
+			from = "|o"
+		}
+		if relation.Nullable {
+			to = "o|"
+		}
+		fmt.Fprintf(body, "%s %s--%s %s : %s\n", relation.From, from, to, relation.To, relation.Field)
+	}
+	body.WriteString("@enduml\n")
+	return body.String()
+}
+
+func erDOT(tables []*erTable, relations []*erRelation) string {
+	body := bytes.NewBufferString("digraph er {\n  rankdir=LR;\n  node [shape=plaintext];\n")
+	for _, table := range tables {
+		title := html.EscapeString(table.Name)
+		if table.CName != "" {
+			title += "<br/>" + html.EscapeString(table.CName)
+		}
+		fmt.Fprintf(body, "\n  %q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">\n", table.Name)
+		fmt.Fprintf(body, "    <tr><td bgcolor=\"lightgrey\" colspan=\"2\"><b>%s</b></td></tr>\n", title)
+		for _, field := range table.Fields {
+			name := html.EscapeString(field.Name)
+			if field.IsPK {
+				name = "<u>" + name + "</u>"
+			}
+			desc := html.EscapeString(field.Type)
+			if keys := field.keys(); len(keys) != 0 {
+				desc += " " + strings.Join(keys, ",")
+			}
+			if !field.Nullable {
+				desc += " NOT NULL"
+			}
+			fmt.Fprintf(body, "    <tr><td port=%q align=\"left\">%s</td><td align=\"left\">%s</td></tr>\n",
+				field.Name, name, desc)
+		}
+		body.WriteString("  </table>>];\n")
+	}
+	if len(relations) != 0 {
+		body.WriteString("\n")
+	}
+	for _, relation := range relations {
+		// 箭头尾部为From表一侧，头部为To表一侧
+		tail, head := "crowodot", "teetee"
+		if relation.IsUnique {
+			tail = "teeodot"
+		}
+		if relation.Nullable {
+			head = "teeodot"
+		}
+		fmt.Fprintf(body, "  %q:%q -> %q [label=%q, dir=both, arrowtail=%s, arrowhead=%s];\n",
+			relation.From, relation.Field, relation.To, relation.Field, tail, head)
+	}
+	body.WriteString("}\n")
+	return body.String()
+}
@@ -0,0 +1,119 @@
+package metacenter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToERDiagram(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name    string
+		param   *ERDiagramParam
+		want    string
+		wantErr bool
+	}{
+		{
+			"mermaid",
+			nil,
+			`erDiagram
+    t_task {
+        uint id PK "自增ID"
+        enum task_status "任务状态"
+        enum phase "任务阶段"
+        json ext "扩展信息"
+        datetime create_time "创建时间"
+    }
+    t_sub_task {
+        uint id PK "自增ID"
+        uint task_id FK "任务ID"
+        enum task_status "任务状态"
+        float64 score "得分"
+    }
+    t_sub_task }o--|| t_task : "task_id"
+`,
+			false,
+		},
+		{
+			"plantuml keys only",
+			&ERDiagramParam{Format: ERDiagramPlantUML, KeysOnly: true},
+			`@startuml
+hide circle
+skinparam linetype ortho
+
+entity "t_task\n任务表" as t_task {
+  * id : uint <<PK>> // 自增ID
+  --
+}
+
+entity "t_sub_task\n子任务表" as t_sub_task {
+  * id : uint <<PK>> // 自增ID
+  --
+  * task_id : uint <<FK>> // 任务ID
+}
+
+t_sub_task }o--|| t_task : task_id
+@enduml
+`,
+			false,
+		},
+		{
+			"dot filter with ref tables",
+			&ERDiagramParam{Format: ERDiagramDOT, Tables: []string{"t_sub_task"}, WithRefTables: true, KeysOnly: true},
+			`digraph er {
+  rankdir=LR;
+  node [shape=plaintext];
+
+  "t_task" [label=<<table border="0" cellborder="1" cellspacing="0">
+    <tr><td bgcolor="lightgrey" colspan="2"><b>t_task<br/>任务表</b></td></tr>
+    <tr><td port="id" align="left"><u>id</u></td><td align="left">uint PK NOT NULL</td></tr>
+  </table>>];
+
+  "t_sub_task" [label=<<table border="0" cellborder="1" cellspacing="0">
+    <tr><td bgcolor="lightgrey" colspan="2"><b>t_sub_task<br/>子任务表</b></td></tr>
+    <tr><td port="id" align="left"><u>id</u></td><td align="left">uint PK NOT NULL</td></tr>
+    <tr><td port="task_id" align="left">task_id</td><td align="left">uint FK NOT NULL</td></tr>
+  </table>>];
+
+  "t_sub_task":"task_id" -> "t_task" [label="task_id", dir=both, arrowtail=crowodot, arrowhead=teetee];
+}
+`,
+			false,
+		},
+		{
+			"filter without ref tables",
+			&ERDiagramParam{Tables: []string{"t_sub_task"}, KeysOnly: true},
+			`erDiagram
+    t_sub_task {
+        uint id PK "自增ID"
+        uint task_id FK "任务ID"
+    }
+`,
+			false,
+		},
+		{
+			"unknown table",
+			&ERDiagramParam{Tables: []string{"t_unknown"}},
+			"",
+			true,
+		},
+		{
+			"unknown format",
+			&ERDiagramParam{Format: "svg"},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.ToERDiagram(ctx, newGenTestTables(), tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultMetaCenter.ToERDiagram() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultMetaCenter.ToERDiagram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// ESIndexModeNone 不分索引，固定使用NameOrPrefix作为索引名
+	ESIndexModeNone = 0
+	// ESIndexModeDay 按IndexFieldID对应日期时间字段的天分索引，如prefix20240102
+	ESIndexModeDay = 1
+	// ESIndexModeMonth 按IndexFieldID对应日期时间字段的月分索引，如prefix202401
+	ESIndexModeMonth = 2
+	// ESIndexModeYear 按IndexFieldID对应日期时间字段的年分索引，如prefix2024
+	ESIndexModeYear = 3
+	// ESIndexModeHash 按IndexFieldID对应字段值的hash分索引，如prefix0...prefix{HashBuckets-1}
+	ESIndexModeHash = 4
+	// ESIndexModeEnum 按IndexFieldID对应枚举字段的值分索引，如prefix1/prefixwaiting
+	ESIndexModeEnum = 5
+)
+
+// ESDateTimeLayout es中日期时间字段的格式，对应es模板中的yyyy-MM-dd HH:mm:ss
+const ESDateTimeLayout = "2006-01-02 15:04:05"
+
+// defaultESHashBuckets 未配置HashBuckets时hash分索引的默认数量
+const defaultESHashBuckets = 16
+
+// maxESTimeRangeIndices 按时间范围列出的分索引数量上限，超过时改用prefix*通配，避免请求url过长
+const maxESTimeRangeIndices = 100
+
+// ESIndexFilter 查询时用于计算需要查询的索引列表的过滤条件
+type ESIndexFilter struct {
+	// StartTime 时间范围开始，按时间分索引时生效，为零值表示不限制
+	StartTime time.Time
+	// EndTime 时间范围结束，按时间分索引时生效，为零值表示不限制
+	EndTime time.Time
+	// Values 分索引字段的取值，按hash/枚举分索引时生效，为空表示不限制
+	Values []interface{}
+}
+
+// GetESIndexName 根据表的分索引配置计算文档应写入的索引名
+func (d *DefaultMetaCenter) GetESIndexName(ctx context.Context, table *Table, doc map[string]interface{}) (string, error) {
+	indexConfig := table.ESConfig.Index
+	if !indexConfig.MultiIndex || indexConfig.IndexMode == ESIndexModeNone {
+		return indexConfig.NameOrPrefix, nil
+	}
+	field, err := d.getESIndexField(ctx, table)
+	if err != nil {
+		return "", err
+	}
+	value, ok := doc[field.Name]
+	if !ok || isNilValue(value) {
+		return "", fmt.Errorf("index field(%s) not found in doc", field.Name)
+	}
+	switch indexConfig.IndexMode {
+	case ESIndexModeDay, ESIndexModeMonth, ESIndexModeYear:
+		t, err := parseESIndexTime(value)
+		if err != nil {
+			return "", fmt.Errorf("parse index field(%s) fail: %w", field.Name, err)
+		}
+		return indexConfig.NameOrPrefix + t.Format(esIndexTimeLayout(indexConfig.IndexMode)), nil
+	case ESIndexModeHash:
+		return indexConfig.NameOrPrefix + strconv.Itoa(esIndexHash(value, indexConfig.HashBuckets)), nil
+	case ESIndexModeEnum:
+		enumValue := esValueString(value)
+		if field.Enum == nil || field.Enum.getValue(enumValue) == nil {
+			return "", fmt.Errorf("index field(%s) value(%s) is not a valid enum value", field.Name, enumValue)
+		}
+		return indexConfig.NameOrPrefix + enumValue, nil
+	}
+	return "", fmt.Errorf("unknown index mode(%d)", indexConfig.IndexMode)
+}
+
+// FindESIndices 根据表的分索引配置以及过滤条件计算需要查询的索引列表
+// 无法缩小范围时返回通配的索引名，如prefix*
+func (d *DefaultMetaCenter) FindESIndices(ctx context.Context, table *Table, filter *ESIndexFilter) ([]string, error) {
+	indexConfig := table.ESConfig.Index
+	if !indexConfig.MultiIndex || indexConfig.IndexMode == ESIndexModeNone {
+		return []string{indexConfig.NameOrPrefix}, nil
+	}
+	field, err := d.getESIndexField(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		filter = &ESIndexFilter{}
+	}
+	allIndex := []string{indexConfig.NameOrPrefix + "*"}
+	switch indexConfig.IndexMode {
+	case ESIndexModeDay, ESIndexModeMonth, ESIndexModeYear:
+		if filter.StartTime.IsZero() || filter.EndTime.IsZero() {
+			return allIndex, nil
+		}
+		if filter.EndTime.Before(filter.StartTime) {
+			return nil, fmt.Errorf("time range end(%s) before start(%s)",
+				filter.EndTime.Format(ESDateTimeLayout), filter.StartTime.Format(ESDateTimeLayout))
+		}
+		return esIndicesByTimeRange(indexConfig.NameOrPrefix, indexConfig.IndexMode, filter.StartTime, filter.EndTime), nil
+	case ESIndexModeHash:
+		if len(filter.Values) == 0 {
+			return allIndex, nil
+		}
+		buckets := make(map[int]bool)
+		for _, value := range filter.Values {
+			buckets[esIndexHash(value, indexConfig.HashBuckets)] = true
+		}
+		ret := make([]string, 0, len(buckets))
+		for bucket := range buckets {
+			ret = append(ret, indexConfig.NameOrPrefix+strconv.Itoa(bucket))
+		}
+		sort.Strings(ret)
+		return ret, nil
+	case ESIndexModeEnum:
+		var values []string
+		if len(filter.Values) == 0 {
+			if field.Enum == nil {
+				return allIndex, nil
+			}
+			for _, enumValue := range field.Enum.Values {
+				values = append(values, enumValue.Value)
+			}
+		}
+		for _, value := range filter.Values {
+			enumValue := esValueString(value)
+			if field.Enum == nil || field.Enum.getValue(enumValue) == nil {
+				return nil, fmt.Errorf("index field(%s) value(%s) is not a valid enum value", field.Name, enumValue)
+			}
+			values = append(values, enumValue)
+		}
+		ret := make([]string, 0, len(values))
+		exists := make(map[string]bool)
+		for _, value := range values {
+			if exists[value] {
+				continue
+			}
+			exists[value] = true
+			ret = append(ret, indexConfig.NameOrPrefix+value)
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("unknown index mode(%d)", indexConfig.IndexMode)
+}
+
+func (d *DefaultMetaCenter) getESIndexField(ctx context.Context, table *Table) (*Field, error) {
+	indexConfig := table.ESConfig.Index
+	field := table.GetFieldByID(indexConfig.IndexFieldID)
+	if field == nil {
+		return nil, fmt.Errorf("index field(%d) not found in table(%s)", indexConfig.IndexFieldID, table.Name)
+	}
+	switch indexConfig.IndexMode {
+	case ESIndexModeDay, ESIndexModeMonth, ESIndexModeYear:
+		if d.dataTypeGetter.GetByID(ctx, field.Type).Name != DataTypeDateTime {
+			return nil, fmt.Errorf("index field(%s) must be datetime when index mode is %d", field.Name, indexConfig.IndexMode)
+		}
+	case ESIndexModeEnum:
+		if field.Enum == nil {
+			return nil, fmt.Errorf("index field(%s) must be enum when index mode is %d", field.Name, indexConfig.IndexMode)
+		}
+	}
+	return field, nil
+}
+
+func esIndexTimeLayout(mode int) string {
+	switch mode {
+	case ESIndexModeDay:
+		return "20060102"
+	case ESIndexModeMonth:
+		return "200601"
+	}
+	return "2006"
+}
+
+// esIndicesByTimeRange 列出[start, end]覆盖的所有时间分索引
+// 数量超过maxESTimeRangeIndices时返回prefix*
+func esIndicesByTimeRange(prefix string, mode int, start, end time.Time) []string {
+	layout := esIndexTimeLayout(mode)
+	var ret []string
+	// 先对齐到每个分索引周期的起点，避免月末等日期加月时跳过
+	cur := time.Date(start.Year(), 1, 1, 0, 0, 0, 0, start.Location())
+	switch mode {
+	case ESIndexModeDay:
+		cur = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	case ESIndexModeMonth:
+		cur = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	}
+	for !cur.After(end) {
+		if len(ret) >= maxESTimeRangeIndices {
+			return []string{prefix + "*"}
+		}
+		ret = append(ret, prefix+cur.Format(layout))
+		switch mode {
+		case ESIndexModeDay:
+			cur = cur.AddDate(0, 0, 1)
+		case ESIndexModeMonth:
+			cur = cur.AddDate(0, 1, 0)
+		default:
+			cur = cur.AddDate(1, 0, 0)
+		}
+	}
+	return ret
+}
+
+// parseESIndexTime 将文档中的日期时间字段值转换为time.Time
+// 支持time.Time、ESDateTimeLayout格式字符串以及秒级时间戳
+func parseESIndexTime(value interface{}) (time.Time, error) {
+	if isNilValue(value) {
+		return time.Time{}, fmt.Errorf("datetime value is nil")
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		return *v, nil
+	case json.Number:
+		ts, err := v.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp(%s): %w", v, err)
+		}
+		return time.Unix(ts, 0), nil
+	case string:
+		return time.ParseInLocation(ESDateTimeLayout, v, time.Local)
+	case int:
+		return time.Unix(int64(v), 0), nil
+	case int64:
+		return time.Unix(v, 0), nil
+	case uint64:
+		return time.Unix(int64(v), 0), nil
+	case float64:
+		return time.Unix(int64(v), 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported datetime value(%v) type(%T)", value, value)
+}
+
+func esIndexHash(value interface{}, buckets int) int {
+	if buckets <= 0 {
+		buckets = defaultESHashBuckets
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(esValueString(value)))
+	return int(h.Sum32() % uint32(buckets))
+}
+
+// esValueString 将字段值转换为字符串，用于文档ID以及hash/枚举分索引
+// 数字统一不使用科学计数法，保证JSON解码得到的float64/json.Number与数据库读取的整数结果一致，如1.234567e+06->1234567
+func esValueString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// isNilValue 是否为nil或值为nil的指针、map、slice等
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newESIndexTestTable(mode int) *Table {
+	table := &Table{
+		Name: "t_task",
+		Fields: []*Field{
+			{ID: 1, Name: "id", Type: 1},
+			{ID: 2, Name: "create_time", Type: 5},
+			{
+				ID:   3,
+				Name: "status",
+				Type: 6,
+				Enum: &Enum{
+					ID:         1,
+					DataTypeID: 1,
+					Values: []*EnumValue{
+						{Value: "1", EName: "wait"},
+						{Value: "2", EName: "finish"},
+					},
+				},
+			},
+		},
+	}
+	table.ESConfig.Index.NameOrPrefix = "task_"
+	table.ESConfig.Index.MultiIndex = true
+	table.ESConfig.Index.IndexMode = mode
+	table.ESConfig.Index.IndexFieldID = 2
+	if mode == ESIndexModeHash || mode == ESIndexModeEnum {
+		table.ESConfig.Index.IndexFieldID = 3
+	}
+	table.ESConfig.Index.HashBuckets = 4
+	return table
+}
+
+func TestDefaultMetaCenter_GetESIndexName(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   *Table
+		doc     map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"single", newESIndexTestTable(ESIndexModeNone), nil, "task_", false},
+		{"day", newESIndexTestTable(ESIndexModeDay), map[string]interface{}{"create_time": "2024-01-02 10:00:00"}, "task_20240102", false},
+		{"month", newESIndexTestTable(ESIndexModeMonth), map[string]interface{}{"create_time": "2024-01-02 10:00:00"}, "task_202401", false},
+		{"year", newESIndexTestTable(ESIndexModeYear), map[string]interface{}{"create_time": "2024-01-02 10:00:00"}, "task_2024", false},
+		{"enum", newESIndexTestTable(ESIndexModeEnum), map[string]interface{}{"status": 2}, "task_2", false},
+		{"enum invalid", newESIndexTestTable(ESIndexModeEnum), map[string]interface{}{"status": 3}, "", true},
+		{"missing field", newESIndexTestTable(ESIndexModeDay), map[string]interface{}{}, "", true},
+		{"bad time", newESIndexTestTable(ESIndexModeDay), map[string]interface{}{"create_time": "2024/01/02"}, "", true},
+	}
+	d := NewDefaultMetaCenter(context.Background(), WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.GetESIndexName(context.Background(), tt.table, tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DefaultMetaCenter.GetESIndexName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DefaultMetaCenter.GetESIndexName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_FindESIndices(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 0, 0, 0, time.Local)
+	hashTable := newESIndexTestTable(ESIndexModeHash)
+	hashIndex, _ := NewDefaultMetaCenter(context.Background(), WithDataTypeGetter(NewDefaultDataTypeGetter())).
+		GetESIndexName(context.Background(), hashTable, map[string]interface{}{"status": 1})
+	tests := []struct {
+		name    string
+		table   *Table
+		filter  *ESIndexFilter
+		want    []string
+		wantErr bool
+	}{
+		{"day", newESIndexTestTable(ESIndexModeDay), &ESIndexFilter{StartTime: start, EndTime: start.AddDate(0, 0, 2)},
+			[]string{"task_20240131", "task_20240201", "task_20240202"}, false},
+		{"month", newESIndexTestTable(ESIndexModeMonth), &ESIndexFilter{StartTime: start, EndTime: start.AddDate(0, 0, 15)},
+			[]string{"task_202401", "task_202402"}, false},
+		{"no range", newESIndexTestTable(ESIndexModeYear), nil, []string{"task_*"}, false},
+		{"day too many", newESIndexTestTable(ESIndexModeDay), &ESIndexFilter{StartTime: start, EndTime: start.AddDate(3, 0, 0)},
+			[]string{"task_*"}, false},
+		{"reverse range", newESIndexTestTable(ESIndexModeDay), &ESIndexFilter{StartTime: start, EndTime: start.AddDate(0, 0, -1)},
+			nil, true},
+		{"hash", hashTable, &ESIndexFilter{Values: []interface{}{1, "1"}}, []string{hashIndex}, false},
+		{"enum all", newESIndexTestTable(ESIndexModeEnum), nil, []string{"task_1", "task_2"}, false},
+		{"enum values", newESIndexTestTable(ESIndexModeEnum), &ESIndexFilter{Values: []interface{}{"2"}}, []string{"task_2"}, false},
+	}
+	d := NewDefaultMetaCenter(context.Background(), WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.FindESIndices(context.Background(), tt.table, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DefaultMetaCenter.FindESIndices() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DefaultMetaCenter.FindESIndices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_esIndicesByTimeRange_Limit(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	got := esIndicesByTimeRange("task_", ESIndexModeDay, start, start.AddDate(0, 0, maxESTimeRangeIndices-1))
+	if len(got) != maxESTimeRangeIndices || got[0] != "task_20240101" {
+		t.Errorf("esIndicesByTimeRange() at limit = %v, want %d indices", got, maxESTimeRangeIndices)
+	}
+	got = esIndicesByTimeRange("task_", ESIndexModeDay, start, start.AddDate(0, 0, maxESTimeRangeIndices))
+	if !reflect.DeepEqual(got, []string{"task_*"}) {
+		t.Errorf("esIndicesByTimeRange() over limit = %v, want [task_*]", got)
+	}
+}
+
+func Test_esIndexValue(t *testing.T) {
+	var nilTime *time.Time
+	if _, err := parseESIndexTime(nilTime); err == nil {
+		t.Errorf("parseESIndexTime(nil *time.Time) should return error")
+	}
+	d := NewDefaultMetaCenter(context.Background(), WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	if _, err := d.GetESIndexName(context.Background(), newESIndexTestTable(ESIndexModeDay),
+		map[string]interface{}{"create_time": nilTime}); err == nil {
+		t.Errorf("GetESIndexName() with nil *time.Time should return error")
+	}
+	ts, err := parseESIndexTime(json.Number("1704160800"))
+	if err != nil || ts.Unix() != 1704160800 {
+		t.Errorf("parseESIndexTime(json.Number) = %v, %v", ts, err)
+	}
+	values := []interface{}{1234567, int64(1234567), float64(1234567), json.Number("1234567"), "1234567"}
+	for _, value := range values {
+		if got := esValueString(value); got != "1234567" {
+			t.Errorf("esValueString(%T) = %s, want 1234567", value, got)
+		}
+		if esIndexHash(value, 16) != esIndexHash(1234567, 16) {
+			t.Errorf("esIndexHash(%T) should equal to the hash of int", value)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package metacenter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ESQueryBuilder 基于字段元数据的es查询构造器
+// 字段名会校验是否属于表，并根据ToESTemplate生成的映射选择term/match/nested查询，
+// 枚举字段可以直接使用枚举描述，日期时间字段的字符串按ESDateTimeLayout解析
+// JSON字段使用"字段名.子字段名"查询，会包装为nested查询，字符串子字段的精确匹配使用动态映射的keyword子字段
+type ESQueryBuilder struct {
+	ctx    context.Context
+	center *DefaultMetaCenter
+	table  *Table
+
+	must    []map[string]interface{}
+	filter  []map[string]interface{}
+	mustNot []map[string]interface{}
+	sorts   []map[string]interface{}
+	from    int
+	size    int
+	errs    []string
+}
+
+// NewESQueryBuilder 实例化表的es查询构造器
+func (d *DefaultMetaCenter) NewESQueryBuilder(ctx context.Context, table *Table) *ESQueryBuilder {
+	return &ESQueryBuilder{
+		ctx:    ctx,
+		center: d,
+		table:  table,
+		size:   -1,
+	}
+}
+
+// esQueryField 解析后的查询字段
+type esQueryField struct {
+	field *Field
+	// mappingType 字段在es中的映射类型
+	mappingType string
+	// path 查询使用的字段路径
+	path string
+	// nestedPath 非空时表示查询需要包装为nested查询
+	nestedPath string
+}
+
+func (b *ESQueryBuilder) addErr(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
+
+func (b *ESQueryBuilder) getField(name string) *esQueryField {
+	fieldName, subName := name, ""
+	if i := strings.Index(name, "."); i > 0 {
+		fieldName, subName = name[:i], name[i+1:]
+	}
+	field := b.table.GetFieldByName(fieldName)
+	if field == nil {
+		b.addErr("field(%s) not found in table(%s)", fieldName, b.table.Name)
+		return nil
+	}
+	mappingType, _ := b.center.esFieldMapping(b.ctx, field)["type"].(string)
+	ret := &esQueryField{field: field, mappingType: mappingType, path: name}
+	if subName == "" {
+		return ret
+	}
+	if mappingType != "nested" {
+		b.addErr("field(%s) is %s and has no sub field(%s)", fieldName, mappingType, subName)
+		return nil
+	}
+	ret.nestedPath = fieldName
+	return ret
+}
+
+// wrap 对nested字段包装nested查询
+func (f *esQueryField) wrap(query map[string]interface{}) map[string]interface{} {
+	if f.nestedPath == "" {
+		return query
+	}
+	return map[string]interface{}{
+		"nested": map[string]interface{}{
+			"path":  f.nestedPath,
+			"query": query,
+		},
+	}
+}
+
+// termPath 获取精确匹配使用的字段路径
+func (f *esQueryField) termPath(value interface{}) string {
+	if f.mappingType == "text" {
+		return f.path + ".keyword"
+	}
+	if _, ok := value.(string); ok && f.nestedPath != "" {
+		return f.path + ".keyword"
+	}
+	return f.path
+}
+
+// convertValue 按字段类型转换查询值：枚举描述转换为枚举值，日期时间转换为ESDateTimeLayout格式
+func (b *ESQueryBuilder) convertValue(f *esQueryField, value interface{}) (interface{}, bool) {
+	if f.nestedPath != "" {
+		return value, true
+	}
+	field := f.field
+	// 按逻辑类型判断，int等类型但有枚举值的字段同样视为枚举
+	switch b.center.getFieldKind(b.ctx, field) {
+	case fieldKindEnum:
+		str := fmt.Sprint(value)
+		enumValue := field.Enum.getValue(str)
+		if enumValue == nil {
+			for _, v := range field.Enum.Values {
+				if v.Desc == str {
+					enumValue = v
+					break
+				}
+			}
+		}
+		if enumValue == nil {
+			b.addErr("value(%s) is neither a value nor a desc of enum field(%s)", str, field.Name)
+			return nil, false
+		}
+		if f.mappingType == "long" {
+			if num, err := strconv.ParseInt(enumValue.Value, 10, 64); err == nil {
+				return num, true
+			}
+		}
+		return enumValue.Value, true
+	case fieldKindDateTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(ESDateTimeLayout), true
+		case string:
+			if _, err := time.ParseInLocation(ESDateTimeLayout, v, time.Local); err != nil {
+				b.addErr("value(%s) of datetime field(%s) not in format %s", v, field.Name, ESDateTimeLayout)
+				return nil, false
+			}
+			return v, true
+		}
+		b.addErr("unsupported value(%v) type(%T) of datetime field(%s)", value, value, field.Name)
+		return nil, false
+	}
+	return value, true
+}
+
+// Eq 字段等于value
+func (b *ESQueryBuilder) Eq(name string, value interface{}) *ESQueryBuilder {
+	if query := b.termQuery(name, value); query != nil {
+		b.filter = append(b.filter, query)
+	}
+	return b
+}
+
+// NotEq 字段不等于value
+func (b *ESQueryBuilder) NotEq(name string, value interface{}) *ESQueryBuilder {
+	if query := b.termQuery(name, value); query != nil {
+		b.mustNot = append(b.mustNot, query)
+	}
+	return b
+}
+
+func (b *ESQueryBuilder) termQuery(name string, value interface{}) map[string]interface{} {
+	f := b.getField(name)
+	if f == nil {
+		return nil
+	}
+	if f.mappingType == "nested" && f.nestedPath == "" {
+		b.addErr("nested field(%s) must be queried by sub field", name)
+		return nil
+	}
+	value, ok := b.convertValue(f, value)
+	if !ok {
+		return nil
+	}
+	return f.wrap(map[string]interface{}{
+		"term": map[string]interface{}{f.termPath(value): value},
+	})
+}
+
+// In 字段等于values中的任意一个
+func (b *ESQueryBuilder) In(name string, values ...interface{}) *ESQueryBuilder {
+	f := b.getField(name)
+	if f == nil {
+		return b
+	}
+	if len(values) == 0 {
+		b.addErr("values of field(%s) cannot be empty", name)
+		return b
+	}
+	converted := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		value, ok := b.convertValue(f, value)
+		if !ok {
+			return b
+		}
+		converted = append(converted, value)
+	}
+	b.filter = append(b.filter, f.wrap(map[string]interface{}{
+		"terms": map[string]interface{}{f.termPath(converted[0]): converted},
+	}))
+	return b
+}
+
+// Match 全文检索，只支持ESFieldType为text的字段以及JSON字段的子字段
+func (b *ESQueryBuilder) Match(name string, text string) *ESQueryBuilder {
+	f := b.getField(name)
+	if f == nil {
+		return b
+	}
+	if f.mappingType != "text" && f.nestedPath == "" {
+		b.addErr("field(%s) is %s, match is only supported by text field", name, f.mappingType)
+		return b
+	}
+	b.must = append(b.must, f.wrap(map[string]interface{}{
+		"match": map[string]interface{}{f.path: text},
+	}))
+	return b
+}
+
+// Range 字段范围查询[gte, lte]，gte或lte为nil表示不限制
+func (b *ESQueryBuilder) Range(name string, gte, lte interface{}) *ESQueryBuilder {
+	f := b.getField(name)
+	if f == nil {
+		return b
+	}
+	switch f.mappingType {
+	case "keyword", "text", "nested":
+		if f.nestedPath == "" {
+			b.addErr("field(%s) is %s, range is not supported", name, f.mappingType)
+			return b
+		}
+	}
+	cond := make(map[string]interface{})
+	for op, value := range map[string]interface{}{"gte": gte, "lte": lte} {
+		if value == nil {
+			continue
+		}
+		value, ok := b.convertValue(f, value)
+		if !ok {
+			return b
+		}
+		cond[op] = value
+	}
+	if len(cond) == 0 {
+		b.addErr("range of field(%s) needs gte or lte", name)
+		return b
+	}
+	if f.mappingType == "date" {
+		cond["format"] = "yyyy-MM-dd HH:mm:ss"
+	}
+	b.filter = append(b.filter, f.wrap(map[string]interface{}{
+		"range": map[string]interface{}{f.path: cond},
+	}))
+	return b
+}
+
+// Exists 字段存在且不为null
+func (b *ESQueryBuilder) Exists(name string) *ESQueryBuilder {
+	f := b.getField(name)
+	if f == nil {
+		return b
+	}
+	b.filter = append(b.filter, f.wrap(map[string]interface{}{
+		"exists": map[string]interface{}{"field": f.path},
+	}))
+	return b
+}
+
+// Sort 按字段排序，text字段使用keyword子字段排序
+func (b *ESQueryBuilder) Sort(name string, desc bool) *ESQueryBuilder {
+	f := b.getField(name)
+	if f == nil {
+		return b
+	}
+	if f.nestedPath != "" || f.mappingType == "nested" {
+		b.addErr("sort by nested field(%s) is not supported", name)
+		return b
+	}
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	path := f.path
+	if f.mappingType == "text" {
+		path += ".keyword"
+	}
+	b.sorts = append(b.sorts, map[string]interface{}{path: map[string]interface{}{"order": order}})
+	return b
+}
+
+// Page 分页，from从0开始
+func (b *ESQueryBuilder) Page(from, size int) *ESQueryBuilder {
+	maxResultWindow := b.table.ESConfig.Index.MaxResultWindow
+	if maxResultWindow != 0 && from+size > maxResultWindow {
+		b.addErr("from(%d)+size(%d) exceeds max_result_window(%d)", from, size, maxResultWindow)
+		return b
+	}
+	b.from = from
+	b.size = size
+	return b
+}
+
+// Build 构造es查询请求体，构造过程中的所有错误会合并返回
+func (b *ESQueryBuilder) Build() (map[string]interface{}, error) {
+	if len(b.errs) != 0 {
+		return nil, fmt.Errorf("build es query of table(%s) fail: %s", b.table.Name, strings.Join(b.errs, "; "))
+	}
+	boolQuery := make(map[string]interface{})
+	if len(b.must) != 0 {
+		boolQuery["must"] = b.must
+	}
+	if len(b.filter) != 0 {
+		boolQuery["filter"] = b.filter
+	}
+	if len(b.mustNot) != 0 {
+		boolQuery["must_not"] = b.mustNot
+	}
+	ret := map[string]interface{}{
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+	}
+	if len(boolQuery) != 0 {
+		ret["query"] = map[string]interface{}{"bool": boolQuery}
+	}
+	if len(b.sorts) != 0 {
+		ret["sort"] = b.sorts
+	}
+	if b.size >= 0 {
+		ret["from"] = b.from
+		ret["size"] = b.size
+	}
+	return ret, nil
+}
@@ -0,0 +1,126 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestESQueryBuilder_Build(t *testing.T) {
+	ctx := context.Background()
+	table := &Table{
+		Name: "t_task",
+		Fields: []*Field{
+			{ID: 1, Name: "id", Type: 1},
+			{ID: 2, Name: "title", Type: 3, ESFieldType: "text"},
+			{ID: 3, Name: "create_time", Type: 5},
+			{
+				ID:   4,
+				Name: "status",
+				Type: 6,
+				Enum: &Enum{
+					DataTypeID: 1,
+					Values: []*EnumValue{
+						{Value: "1", Desc: "待执行"},
+						{Value: "2", Desc: "已完成"},
+					},
+				},
+			},
+			{ID: 5, Name: "ext", Type: 7},
+			{ID: 6, Name: "operator", Type: 3},
+			{
+				ID:   7,
+				Name: "priority",
+				Type: 1,
+				Enum: &Enum{
+					DataTypeID: 1,
+					Values: []*EnumValue{
+						{Value: "1", Desc: "低"},
+						{Value: "2", Desc: "高"},
+					},
+				},
+			},
+		},
+	}
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name    string
+		build   func(b *ESQueryBuilder) *ESQueryBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			"match all",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b },
+			`{"query":{"match_all":{}}}`,
+			false,
+		},
+		{
+			"term and match",
+			func(b *ESQueryBuilder) *ESQueryBuilder {
+				return b.Eq("status", "已完成").Eq("title", "abc").Match("title", "abc").NotEq("operator", "x")
+			},
+			`{"query":{"bool":{"filter":[{"term":{"status":2}},{"term":{"title.keyword":"abc"}}],` +
+				`"must":[{"match":{"title":"abc"}}],"must_not":[{"term":{"operator":"x"}}]}}}`,
+			false,
+		},
+		{
+			"int field with enum",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b.Eq("priority", "高").In("priority", "低", 2) },
+			`{"query":{"bool":{"filter":[{"term":{"priority":2}},{"terms":{"priority":[1,2]}}]}}}`,
+			false,
+		},
+		{
+			"range and nested",
+			func(b *ESQueryBuilder) *ESQueryBuilder {
+				return b.Range("create_time", "2024-01-01 00:00:00", nil).In("ext.tag", "a", "b").
+					Sort("title", true).Page(0, 10)
+			},
+			`{"from":0,"query":{"bool":{"filter":[` +
+				`{"range":{"create_time":{"format":"yyyy-MM-dd HH:mm:ss","gte":"2024-01-01 00:00:00"}}},` +
+				`{"nested":{"path":"ext","query":{"terms":{"ext.tag.keyword":["a","b"]}}}}]}},` +
+				`"size":10,"sort":[{"title.keyword":{"order":"desc"}}]}`,
+			false,
+		},
+		{
+			"unknown field",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b.Eq("unknown", 1) },
+			"",
+			true,
+		},
+		{
+			"invalid enum",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b.Eq("status", "不存在") },
+			"",
+			true,
+		},
+		{
+			"invalid datetime",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b.Range("create_time", "2024/01/01", nil) },
+			"",
+			true,
+		},
+		{
+			"match keyword",
+			func(b *ESQueryBuilder) *ESQueryBuilder { return b.Match("operator", "x") },
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(d.NewESQueryBuilder(ctx, table)).Build()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ESQueryBuilder.Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			body, _ := json.Marshal(got)
+			if string(body) != tt.want {
+				t.Errorf("ESQueryBuilder.Build() = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1051 @@
+package metacenter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ESSyncModeNone 不同步
+	ESSyncModeNone = 0
+	// ESSyncModeFull 只做全量同步
+	ESSyncModeFull = 1
+	// ESSyncModeIncr 只做增量同步
+	ESSyncModeIncr = 2
+	// ESSyncModeFullAndIncr 先全量同步，完成后持续增量同步
+	ESSyncModeFullAndIncr = 3
+)
+
+const (
+	// ESSyncEventInsert 新增事件
+	ESSyncEventInsert = "insert"
+	// ESSyncEventUpdate 更新事件
+	ESSyncEventUpdate = "update"
+	// ESSyncEventDelete 删除事件
+	ESSyncEventDelete = "delete"
+)
+
+const (
+	// ESBulkOpIndex bulk写入/覆盖文档
+	ESBulkOpIndex = "index"
+	// ESBulkOpDelete bulk删除文档
+	ESBulkOpDelete = "delete"
+)
+
+// ErrESSyncStreamIdle 变更流暂时没有事件时返回，同步器会先刷新已缓存的事件再继续拉取
+var ErrESSyncStreamIdle = errors.New("change stream idle")
+
+// ESSyncChangeEvent 表数据变更事件，如一条binlog行事件
+type ESSyncChangeEvent struct {
+	// Table 表英文名
+	Table string `json:"table"`
+	// Type 事件类型，见ESSyncEventXXX
+	Type string `json:"type"`
+	// Row 变更后的行数据，删除事件为删除前的行数据，至少需要包含主键
+	Row map[string]interface{} `json:"row"`
+	// Position 事件在变更流中的位点，用于断点续传
+	Position string `json:"position"`
+}
+
+// ESSyncChangeStream 数据变更流接口，如binlog消费者
+type ESSyncChangeStream interface {
+	// Seek 从指定位点之后开始消费，位点为空表示从头开始
+	Seek(ctx context.Context, position string) error
+	// Next 获取下一个事件，暂时没有事件时返回ErrESSyncStreamIdle，流结束时返回io.EOF
+	Next(ctx context.Context) (*ESSyncChangeEvent, error)
+}
+
+// ESSyncPositioner 可获取最新位点的变更流，先全量后增量同步时在全量开始前记录最新位点，
+// 增量从该位点之后开始，全量期间的变更不会丢失；变更流未实现该接口时增量从头开始消费
+type ESSyncPositioner interface {
+	// LatestPosition 获取当前最新事件的位点，没有事件时返回空
+	LatestPosition(ctx context.Context) (string, error)
+}
+
+// ESSyncPositionComparer 可比较位点先后的变更流，位点格式无法按comparePosition的规则比较时实现，
+// 返回值小于0、等于0、大于0分别表示a在b之前、相同、之后
+type ESSyncPositionComparer interface {
+	ComparePosition(a, b string) int
+}
+
+// ESSyncRowSource 全量同步时的数据源接口
+type ESSyncRowSource interface {
+	// FetchRows 按主键升序获取afterPK之后的最多limit行数据，afterPK为nil表示从头开始
+	FetchRows(ctx context.Context, table *Table, afterPK interface{}, limit int) ([]map[string]interface{}, error)
+}
+
+// ESBulkAction es bulk操作
+type ESBulkAction struct {
+	// Op 操作类型，见ESBulkOpXXX
+	Op string
+	// Index 索引名
+	Index string
+	// ID 文档ID
+	ID string
+	// Doc 文档内容，删除操作为空
+	Doc map[string]interface{}
+}
+
+// ESBulkWriter es批量写入接口
+type ESBulkWriter interface {
+	// Bulk 批量写入
+	Bulk(ctx context.Context, table *Table, actions []*ESBulkAction) error
+}
+
+// ESSyncCheckpoint 同步断点信息
+type ESSyncCheckpoint struct {
+	// Table 表英文名
+	Table string `json:"table"`
+	// FullLoadDone 全量同步是否已完成
+	FullLoadDone bool `json:"full_load_done"`
+	// LastPK 全量同步已完成的最后一个主键值
+	LastPK interface{} `json:"last_pk"`
+	// Position 增量同步已完成的变更流位点
+	Position string `json:"position"`
+	// UpdateTime 更新时间
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// ESSyncCheckpointer 同步断点存储接口
+type ESSyncCheckpointer interface {
+	// Load 获取表的断点信息，不存在时返回nil
+	Load(ctx context.Context, table string) (*ESSyncCheckpoint, error)
+	// Save 保存表的断点信息
+	Save(ctx context.Context, checkpoint *ESSyncCheckpoint) error
+}
+
+// ESSyncRetry 重试配置，重试间隔从InitialBackoff开始指数增长，不超过MaxBackoff
+type ESSyncRetry struct {
+	// MaxRetries 最大重试次数，不包含首次执行
+	MaxRetries int
+	// InitialBackoff 首次重试间隔
+	InitialBackoff time.Duration
+	// MaxBackoff 最大重试间隔
+	MaxBackoff time.Duration
+}
+
+// ESSyncStats 表同步统计
+type ESSyncStats struct {
+	// FullLoadRows 全量同步的行数
+	FullLoadRows int64
+	// IncrEvents 增量同步的事件数
+	IncrEvents int64
+	// BulkRequests bulk请求成功次数
+	BulkRequests int64
+	// BulkFailures bulk请求最终失败次数
+	BulkFailures int64
+	// Retries 重试次数
+	Retries int64
+	// LastSyncTime 最后一次写入成功的时间
+	LastSyncTime time.Time
+}
+
+type esSyncMetrics struct {
+	fullLoadRows int64
+	incrEvents   int64
+	bulkRequests int64
+	bulkFailures int64
+	retries      int64
+	lastSyncTime int64
+}
+
+// ESSyncer mysql到es的同步器，同步的表、字段、索引以及同步方式均由元数据决定
+type ESSyncer struct {
+	center       *DefaultMetaCenter
+	rowSource    ESSyncRowSource
+	writer       ESBulkWriter
+	checkpointer ESSyncCheckpointer
+	retry        ESSyncRetry
+	pageSize     int
+	bulkSize     int
+	idleWait     time.Duration
+
+	mu      sync.Mutex
+	metrics map[string]*esSyncMetrics
+}
+
+// ESSyncerOption 可选参数
+type ESSyncerOption func(*ESSyncer)
+
+// WithESSyncRowSource 指定全量同步数据源
+func WithESSyncRowSource(rs ESSyncRowSource) ESSyncerOption {
+	return func(s *ESSyncer) {
+		s.rowSource = rs
+	}
+}
+
+// WithESBulkWriter 指定es写入器
+func WithESBulkWriter(w ESBulkWriter) ESSyncerOption {
+	return func(s *ESSyncer) {
+		s.writer = w
+	}
+}
+
+// WithESSyncCheckpointer 指定断点存储
+func WithESSyncCheckpointer(cp ESSyncCheckpointer) ESSyncerOption {
+	return func(s *ESSyncer) {
+		s.checkpointer = cp
+	}
+}
+
+// WithESSyncRetry 指定重试配置
+func WithESSyncRetry(retry ESSyncRetry) ESSyncerOption {
+	return func(s *ESSyncer) {
+		s.retry = retry
+	}
+}
+
+// WithESSyncBatch 指定全量同步每页行数以及每次bulk的最大操作数
+func WithESSyncBatch(pageSize, bulkSize int) ESSyncerOption {
+	return func(s *ESSyncer) {
+		s.pageSize = pageSize
+		s.bulkSize = bulkSize
+	}
+}
+
+// NewESSyncer 实例化同步器，默认使用database/sql的mysql驱动读取数据，http写入es，断点保存在内存中
+func NewESSyncer(center *DefaultMetaCenter, opts ...ESSyncerOption) *ESSyncer {
+	s := &ESSyncer{
+		center:       center,
+		rowSource:    NewSQLESSyncRowSource("mysql"),
+		writer:       NewHTTPESBulkWriter(nil),
+		checkpointer: NewMemoryESSyncCheckpointer(),
+		retry: ESSyncRetry{
+			MaxRetries:     3,
+			InitialBackoff: 100 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+		},
+		pageSize: 1000,
+		bulkSize: 500,
+		idleWait: time.Second,
+		metrics:  make(map[string]*esSyncMetrics),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Stats 获取各表的同步统计，表英文名->统计
+func (s *ESSyncer) Stats() map[string]ESSyncStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ret := make(map[string]ESSyncStats, len(s.metrics))
+	for name, m := range s.metrics {
+		stats := ESSyncStats{
+			FullLoadRows: atomic.LoadInt64(&m.fullLoadRows),
+			IncrEvents:   atomic.LoadInt64(&m.incrEvents),
+			BulkRequests: atomic.LoadInt64(&m.bulkRequests),
+			BulkFailures: atomic.LoadInt64(&m.bulkFailures),
+			Retries:      atomic.LoadInt64(&m.retries),
+		}
+		if last := atomic.LoadInt64(&m.lastSyncTime); last != 0 {
+			stats.LastSyncTime = time.Unix(0, last)
+		}
+		ret[name] = stats
+	}
+	return ret
+}
+
+func (s *ESSyncer) getMetrics(table string) *esSyncMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.metrics[table]
+	if !ok {
+		m = &esSyncMetrics{}
+		s.metrics[table] = m
+	}
+	return m
+}
+
+// Run 按各表的ESConfig.Sync执行同步：需要全量的表先完成全量，然后消费stream做增量，stream为nil时只做全量
+func (s *ESSyncer) Run(ctx context.Context, tables []*Table, stream ESSyncChangeStream) error {
+	var incrTables []*Table
+	for _, table := range tables {
+		mode := table.ESConfig.Sync
+		if mode == ESSyncModeFullAndIncr && stream != nil {
+			if err := s.recordStartPosition(ctx, table, stream); err != nil {
+				return err
+			}
+		}
+		if mode == ESSyncModeFull || mode == ESSyncModeFullAndIncr {
+			if err := s.FullLoad(ctx, table); err != nil {
+				return err
+			}
+		}
+		if mode == ESSyncModeIncr || mode == ESSyncModeFullAndIncr {
+			incrTables = append(incrTables, table)
+		}
+	}
+	if stream == nil || len(incrTables) == 0 {
+		return nil
+	}
+	return s.RunIncremental(ctx, incrTables, stream)
+}
+
+// recordStartPosition 全量同步开始前记录变更流的最新位点，增量从该位点之后开始
+// 全量已完成或已记录过位点(全量中断后继续)时不再记录
+func (s *ESSyncer) recordStartPosition(ctx context.Context, table *Table, stream ESSyncChangeStream) error {
+	positioner, ok := stream.(ESSyncPositioner)
+	if !ok {
+		return nil
+	}
+	checkpoint, err := s.loadCheckpoint(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	if checkpoint.FullLoadDone || checkpoint.Position != "" {
+		return nil
+	}
+	if checkpoint.Position, err = positioner.LatestPosition(ctx); err != nil {
+		return errors.Wrapf(err, "get latest position of change stream fail")
+	}
+	return s.saveCheckpoint(ctx, checkpoint)
+}
+
+// FullLoad 按主键分页全量同步表数据，支持从断点继续
+func (s *ESSyncer) FullLoad(ctx context.Context, table *Table) error {
+	pkField, err := s.getSinglePKField(table)
+	if err != nil {
+		return err
+	}
+	checkpoint, err := s.loadCheckpoint(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	if checkpoint.FullLoadDone {
+		return nil
+	}
+	metrics := s.getMetrics(table.Name)
+	for {
+		var rows []map[string]interface{}
+		err := s.withRetry(ctx, metrics, func() error {
+			var err error
+			rows, err = s.rowSource.FetchRows(ctx, table, checkpoint.LastPK, s.pageSize)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "fetch rows of table(%s) after pk(%v) fail", table.Name, checkpoint.LastPK)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		actions := make([]*ESBulkAction, 0, len(rows))
+		for _, row := range rows {
+			action, err := s.toBulkAction(ctx, table, ESSyncEventInsert, row)
+			if err != nil {
+				return err
+			}
+			actions = append(actions, action)
+		}
+		if err := s.bulk(ctx, table, metrics, actions); err != nil {
+			return err
+		}
+		atomic.AddInt64(&metrics.fullLoadRows, int64(len(rows)))
+		checkpoint.LastPK = rows[len(rows)-1][pkField.Name]
+		if err := s.saveCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+		if len(rows) < s.pageSize {
+			break
+		}
+	}
+	checkpoint.FullLoadDone = true
+	return s.saveCheckpoint(ctx, checkpoint)
+}
+
+// RunIncremental 消费变更流增量同步，直到ctx结束或变更流返回io.EOF
+// 从各表断点中最早的位点开始消费，已同步过的事件会根据表的断点跳过
+func (s *ESSyncer) RunIncremental(ctx context.Context, tables []*Table, stream ESSyncChangeStream) error {
+	nameTables := make(map[string]*Table, len(tables))
+	checkpoints := make(map[string]*ESSyncCheckpoint, len(tables))
+	compare := comparePosition
+	if comparer, ok := stream.(ESSyncPositionComparer); ok {
+		compare = comparer.ComparePosition
+	}
+	startPosition := ""
+	for i, table := range tables {
+		checkpoint, err := s.loadCheckpoint(ctx, table.Name)
+		if err != nil {
+			return err
+		}
+		nameTables[table.Name] = table
+		checkpoints[table.Name] = checkpoint
+		if i == 0 || compare(checkpoint.Position, startPosition) < 0 {
+			startPosition = checkpoint.Position
+		}
+	}
+	if err := stream.Seek(ctx, startPosition); err != nil {
+		return errors.Wrapf(err, "seek change stream to position(%s) fail", startPosition)
+	}
+	pending := make(map[string][]*ESBulkAction)
+	positions := make(map[string]string)
+	flush := func(name string) error {
+		if len(pending[name]) == 0 {
+			return nil
+		}
+		table := nameTables[name]
+		if err := s.bulk(ctx, table, s.getMetrics(name), pending[name]); err != nil {
+			return err
+		}
+		atomic.AddInt64(&s.getMetrics(name).incrEvents, int64(len(pending[name])))
+		pending[name] = nil
+		checkpoints[name].Position = positions[name]
+		return s.saveCheckpoint(ctx, checkpoints[name])
+	}
+	flushAll := func() error {
+		for name := range pending {
+			if err := flush(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		// ctx结束时未刷新的事件没有记录断点，下次启动会重新消费
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		event, err := stream.Next(ctx)
+		if err == io.EOF {
+			return flushAll()
+		}
+		if err == ErrESSyncStreamIdle {
+			if err := flushAll(); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(s.idleWait):
+			}
+			continue
+		}
+		if err != nil {
+			if flushErr := flushAll(); flushErr != nil {
+				return flushErr
+			}
+			return errors.Wrapf(err, "read change stream fail")
+		}
+		table, ok := nameTables[event.Table]
+		if !ok || compare(event.Position, checkpoints[event.Table].Position) <= 0 {
+			continue
+		}
+		action, err := s.toBulkAction(ctx, table, event.Type, event.Row)
+		if err != nil {
+			return err
+		}
+		pending[table.Name] = append(pending[table.Name], action)
+		positions[table.Name] = event.Position
+		if len(pending[table.Name]) >= s.bulkSize {
+			if err := flush(table.Name); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *ESSyncer) loadCheckpoint(ctx context.Context, table string) (*ESSyncCheckpoint, error) {
+	checkpoint, err := s.checkpointer.Load(ctx, table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load checkpoint of table(%s) fail", table)
+	}
+	if checkpoint == nil {
+		checkpoint = &ESSyncCheckpoint{Table: table}
+	}
+	return checkpoint, nil
+}
+
+func (s *ESSyncer) saveCheckpoint(ctx context.Context, checkpoint *ESSyncCheckpoint) error {
+	checkpoint.UpdateTime = time.Now()
+	if err := s.checkpointer.Save(ctx, checkpoint); err != nil {
+		return errors.Wrapf(err, "save checkpoint of table(%s) fail", checkpoint.Table)
+	}
+	return nil
+}
+
+func (s *ESSyncer) bulk(ctx context.Context, table *Table, metrics *esSyncMetrics, actions []*ESBulkAction) error {
+	err := s.withRetry(ctx, metrics, func() error {
+		return s.writer.Bulk(ctx, table, actions)
+	})
+	if err != nil {
+		atomic.AddInt64(&metrics.bulkFailures, 1)
+		return errors.Wrapf(err, "bulk write table(%s) fail", table.Name)
+	}
+	atomic.AddInt64(&metrics.bulkRequests, 1)
+	atomic.StoreInt64(&metrics.lastSyncTime, time.Now().UnixNano())
+	return nil
+}
+
+func (s *ESSyncer) withRetry(ctx context.Context, metrics *esSyncMetrics, fn func() error) error {
+	backoff := s.retry.InitialBackoff
+	var err error
+	for i := 0; ; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i >= s.retry.MaxRetries {
+			return err
+		}
+		atomic.AddInt64(&metrics.retries, 1)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if s.retry.MaxBackoff > 0 && backoff > s.retry.MaxBackoff {
+			backoff = s.retry.MaxBackoff
+		}
+	}
+}
+
+func (s *ESSyncer) getSinglePKField(table *Table) (*Field, error) {
+	var pkFields []*Field
+	for _, field := range table.Fields {
+		if field.IsPK {
+			pkFields = append(pkFields, field)
+		}
+	}
+	if len(pkFields) != 1 {
+		return nil, fmt.Errorf("table(%s) must have exactly one pk field to sync, got %d", table.Name, len(pkFields))
+	}
+	return pkFields[0], nil
+}
+
+// toBulkAction 将行数据按字段元数据转换为es文档，文档ID为主键值，多个主键以_连接
+func (s *ESSyncer) toBulkAction(ctx context.Context, table *Table, eventType string, row map[string]interface{}) (*ESBulkAction, error) {
+	doc := make(map[string]interface{}, len(table.Fields))
+	var ids []string
+	for _, field := range table.Fields {
+		value, ok := row[field.Name]
+		if !ok {
+			continue
+		}
+		value, err := s.toESValue(ctx, field, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "convert field(%s) of table(%s) fail", field.Name, table.Name)
+		}
+		doc[field.Name] = value
+		if field.IsPK {
+			if value == nil {
+				return nil, fmt.Errorf("pk field(%s) of table(%s) is null", field.Name, table.Name)
+			}
+			ids = append(ids, esValueString(value))
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("pk of table(%s) not found in row", table.Name)
+	}
+	index, err := s.center.GetESIndexName(ctx, table, doc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get index name of table(%s) fail", table.Name)
+	}
+	action := &ESBulkAction{
+		Op:    ESBulkOpIndex,
+		Index: index,
+		ID:    strings.Join(ids, "_"),
+		Doc:   doc,
+	}
+	switch eventType {
+	case ESSyncEventInsert, ESSyncEventUpdate:
+	case ESSyncEventDelete:
+		action.Op = ESBulkOpDelete
+		action.Doc = nil
+	default:
+		return nil, fmt.Errorf("unknown event type(%s)", eventType)
+	}
+	return action, nil
+}
+
+func (s *ESSyncer) toESValue(ctx context.Context, field *Field, value interface{}) (interface{}, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch s.center.dataTypeGetter.GetByID(ctx, field.Type).Name {
+	case DataTypeDateTime:
+		if t, ok := value.(time.Time); ok {
+			return t.Format(ESDateTimeLayout), nil
+		}
+	case DataTypeJSON:
+		if str, ok := value.(string); ok {
+			if str == "" {
+				return nil, nil
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(str), &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	}
+	return value, nil
+}
+
+// comparePosition 比较两个位点，其中连续的数字按数值比较，其余字符按字节比较，空位点最小，
+// 如binlog位点mysql-bin.000001:999在mysql-bin.000001:1234之前
+func comparePosition(a, b string) int {
+	for a != "" && b != "" {
+		if isASCIIDigit(a[0]) && isASCIIDigit(b[0]) {
+			aNum, bNum := leadingDigits(a), leadingDigits(b)
+			a, b = a[len(aNum):], b[len(bNum):]
+			aNum, bNum = strings.TrimLeft(aNum, "0"), strings.TrimLeft(bNum, "0")
+			if len(aNum) != len(bNum) {
+				if len(aNum) < len(bNum) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(aNum, bNum); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+func isASCIIDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// leadingDigits s开头的连续数字
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && isASCIIDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+// SQLESSyncRowSource 基于database/sql的全量同步数据源，使用表DBConfig的只读账号连接
+// DBConfig.Address格式为host:port/dbname，需要调用方引入对应的驱动
+type SQLESSyncRowSource struct {
+	driverName string
+
+	mu  sync.Mutex
+	dbs map[string]*sql.DB
+}
+
+// NewSQLESSyncRowSource 实例化基于database/sql的全量同步数据源
+func NewSQLESSyncRowSource(driverName string) *SQLESSyncRowSource {
+	return &SQLESSyncRowSource{
+		driverName: driverName,
+		dbs:        make(map[string]*sql.DB),
+	}
+}
+
+func (r *SQLESSyncRowSource) getDB(table *Table) (*sql.DB, error) {
+	config := table.DBConfig
+	address := config.Address
+	dbName := ""
+	if i := strings.Index(address, "/"); i >= 0 {
+		address, dbName = address[:i], address[i+1:]
+	}
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s", config.ReadUser, config.ReadPassword, address, dbName)
+	if config.Charset != "" {
+		dsn += "?charset=" + config.Charset
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if db, ok := r.dbs[dsn]; ok {
+		return db, nil
+	}
+	db, err := sql.Open(r.driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	r.dbs[dsn] = db
+	return db, nil
+}
+
+// FetchRows 按主键升序获取afterPK之后的最多limit行数据
+func (r *SQLESSyncRowSource) FetchRows(ctx context.Context, table *Table, afterPK interface{}, limit int) ([]map[string]interface{}, error) {
+	db, err := r.getDB(table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open db of table(%s) fail", table.Name)
+	}
+	var pkName string
+	columns := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		if field.IsPK {
+			pkName = field.Name
+		}
+		columns = append(columns, "`"+field.Name+"`")
+	}
+	if pkName == "" {
+		return nil, fmt.Errorf("pk of table(%s) not found", table.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM `%s`", strings.Join(columns, ","), table.Name)
+	var args []interface{}
+	if afterPK != nil {
+		query += fmt.Sprintf(" WHERE `%s` > ?", pkName)
+		args = append(args, afterPK)
+	}
+	query += fmt.Sprintf(" ORDER BY `%s` LIMIT ?", pkName)
+	args = append(args, limit)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ret []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(table.Fields))
+		dest := make([]interface{}, len(table.Fields))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(table.Fields))
+		for i, field := range table.Fields {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[field.Name] = values[i]
+		}
+		ret = append(ret, row)
+	}
+	return ret, rows.Err()
+}
+
+// HTTPESBulkWriter 通过es的_bulk接口写入，地址及账号使用表的ESConfig
+type HTTPESBulkWriter struct {
+	client *http.Client
+}
+
+// NewHTTPESBulkWriter 实例化http es写入器，client为nil时使用http.DefaultClient
+func NewHTTPESBulkWriter(client *http.Client) *HTTPESBulkWriter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPESBulkWriter{client: client}
+}
+
+// Bulk 批量写入
+func (w *HTTPESBulkWriter) Bulk(ctx context.Context, table *Table, actions []*ESBulkAction) error {
+	body := bytes.NewBuffer(nil)
+	encoder := json.NewEncoder(body)
+	for _, action := range actions {
+		meta := map[string]map[string]string{
+			action.Op: {"_index": action.Index, "_id": action.ID},
+		}
+		if err := encoder.Encode(meta); err != nil {
+			return err
+		}
+		if action.Op == ESBulkOpDelete {
+			continue
+		}
+		if err := encoder.Encode(action.Doc); err != nil {
+			return err
+		}
+	}
+	url := strings.TrimRight(table.ESConfig.Address, "/") + "/_bulk"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if table.ESConfig.User != "" {
+		req.SetBasicAuth(table.ESConfig.User, table.ESConfig.Password)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bulk fail, status(%d) body(%s)", resp.StatusCode, respBody)
+	}
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return errors.Wrapf(err, "decode bulk response fail")
+	}
+	if result.Errors {
+		return fmt.Errorf("bulk has item errors, body(%s)", respBody)
+	}
+	return nil
+}
+
+// MemoryESSyncChangeStream 内存变更流，用于测试或由其他组件推送事件
+// 事件位点为空时按推入顺序自动生成递增的数字位点
+type MemoryESSyncChangeStream struct {
+	mu     sync.Mutex
+	events []*ESSyncChangeEvent
+	offset int
+	closed bool
+}
+
+// NewMemoryESSyncChangeStream 实例化内存变更流
+func NewMemoryESSyncChangeStream() *MemoryESSyncChangeStream {
+	return &MemoryESSyncChangeStream{}
+}
+
+// Push 推入事件
+func (m *MemoryESSyncChangeStream) Push(events ...*ESSyncChangeEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range events {
+		if event.Position == "" {
+			event.Position = strconv.Itoa(len(m.events) + 1)
+		}
+		m.events = append(m.events, event)
+	}
+}
+
+// Close 关闭变更流，剩余事件消费完后Next返回io.EOF
+func (m *MemoryESSyncChangeStream) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+}
+
+// LatestPosition 获取最后一个事件的位点
+func (m *MemoryESSyncChangeStream) LatestPosition(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.events) == 0 {
+		return "", nil
+	}
+	return m.events[len(m.events)-1].Position, nil
+}
+
+// Seek 从指定位点之后开始消费
+func (m *MemoryESSyncChangeStream) Seek(ctx context.Context, position string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offset = 0
+	for m.offset < len(m.events) && comparePosition(m.events[m.offset].Position, position) <= 0 {
+		m.offset++
+	}
+	return nil
+}
+
+// Next 获取下一个事件
+func (m *MemoryESSyncChangeStream) Next(ctx context.Context) (*ESSyncChangeEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.offset < len(m.events) {
+		m.offset++
+		return m.events[m.offset-1], nil
+	}
+	if m.closed {
+		return nil, io.EOF
+	}
+	return nil, ErrESSyncStreamIdle
+}
+
+// FileESSyncChangeStream 文件变更流，文件每行为一个json格式的ESSyncChangeEvent，位点为行号
+type FileESSyncChangeStream struct {
+	path    string
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewFileESSyncChangeStream 实例化文件变更流
+func NewFileESSyncChangeStream(path string) *FileESSyncChangeStream {
+	return &FileESSyncChangeStream{path: path}
+}
+
+// Seek 从指定行号之后开始消费
+func (f *FileESSyncChangeStream) Seek(ctx context.Context, position string) error {
+	skip := 0
+	if position != "" {
+		var err error
+		if skip, err = strconv.Atoi(position); err != nil {
+			return errors.Wrapf(err, "invalid position(%s)", position)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.scanner = bufio.NewScanner(file)
+	f.scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	f.line = 0
+	for f.line < skip && f.scanner.Scan() {
+		f.line++
+	}
+	return f.scanner.Err()
+}
+
+// Next 获取下一个事件，文件读完时返回io.EOF
+func (f *FileESSyncChangeStream) Next(ctx context.Context) (*ESSyncChangeEvent, error) {
+	if f.scanner == nil {
+		if err := f.Seek(ctx, ""); err != nil {
+			return nil, err
+		}
+	}
+	for f.scanner.Scan() {
+		f.line++
+		line := bytes.TrimSpace(f.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		event := &ESSyncChangeEvent{}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		// 数字保留为json.Number，避免整数主键转为float64后文档ID与全量同步不一致
+		decoder.UseNumber()
+		if err := decoder.Decode(event); err != nil {
+			return nil, errors.Wrapf(err, "decode line(%d) of file(%s) fail", f.line, f.path)
+		}
+		event.Position = strconv.Itoa(f.line)
+		return event, nil
+	}
+	if err := f.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// LatestPosition 获取文件当前的行数
+func (f *FileESSyncChangeStream) LatestPosition(ctx context.Context) (string, error) {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if lines == 0 {
+		return "", nil
+	}
+	return strconv.Itoa(lines), nil
+}
+
+// Close 关闭文件
+func (f *FileESSyncChangeStream) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	f.scanner = nil
+	return err
+}
+
+// MemoryESSyncCheckpointer 内存断点存储
+type MemoryESSyncCheckpointer struct {
+	mu          sync.Mutex
+	checkpoints map[string]ESSyncCheckpoint
+}
+
+// NewMemoryESSyncCheckpointer 实例化内存断点存储
+func NewMemoryESSyncCheckpointer() *MemoryESSyncCheckpointer {
+	return &MemoryESSyncCheckpointer{checkpoints: make(map[string]ESSyncCheckpoint)}
+}
+
+// Load 获取表的断点信息
+func (m *MemoryESSyncCheckpointer) Load(ctx context.Context, table string) (*ESSyncCheckpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	checkpoint, ok := m.checkpoints[table]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+// Save 保存表的断点信息
+func (m *MemoryESSyncCheckpointer) Save(ctx context.Context, checkpoint *ESSyncCheckpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[checkpoint.Table] = *checkpoint
+	return nil
+}
+
+// FileESSyncCheckpointer 文件断点存储，每个表一个{table}.checkpoint.json文件
+type FileESSyncCheckpointer struct {
+	dirPath string
+}
+
+// NewFileESSyncCheckpointer 实例化文件断点存储
+func NewFileESSyncCheckpointer(dirPath string) *FileESSyncCheckpointer {
+	return &FileESSyncCheckpointer{dirPath: dirPath}
+}
+
+func (f *FileESSyncCheckpointer) filePath(table string) string {
+	return filepath.Join(f.dirPath, table+".checkpoint.json")
+}
+
+// Load 获取表的断点信息
+func (f *FileESSyncCheckpointer) Load(ctx context.Context, table string) (*ESSyncCheckpoint, error) {
+	body, err := os.ReadFile(f.filePath(table))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &ESSyncCheckpoint{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// 主键保留原始数字，避免大整数转float64丢失精度
+	decoder.UseNumber()
+	if err := decoder.Decode(checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// Save 保存表的断点信息，先写临时文件再重命名，避免写一半的断点
+func (f *FileESSyncCheckpointer) Save(ctx context.Context, checkpoint *ESSyncCheckpoint) error {
+	if err := os.MkdirAll(f.dirPath, 0777); err != nil {
+		return err
+	}
+	body, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	filePath := f.filePath(checkpoint.Table)
+	if err := os.WriteFile(filePath+".tmp", body, 0666); err != nil {
+		return err
+	}
+	return os.Rename(filePath+".tmp", filePath)
+}
@@ -0,0 +1,232 @@
+package metacenter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+type testESSyncRowSource struct {
+	rows []map[string]interface{}
+	// onFetch 每次获取数据前调用，用于模拟全量同步期间发生的变更
+	onFetch func()
+}
+
+func (r *testESSyncRowSource) FetchRows(ctx context.Context, table *Table, afterPK interface{}, limit int) ([]map[string]interface{}, error) {
+	if r.onFetch != nil {
+		r.onFetch()
+		r.onFetch = nil
+	}
+	var ret []map[string]interface{}
+	for _, row := range r.rows {
+		if afterPK != nil && row["id"].(int) <= afterPK.(int) {
+			continue
+		}
+		if len(ret) == limit {
+			break
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+type testESBulkWriter struct {
+	failTimes int
+	docs      map[string]map[string]interface{}
+}
+
+func (w *testESBulkWriter) Bulk(ctx context.Context, table *Table, actions []*ESBulkAction) error {
+	if w.failTimes > 0 {
+		w.failTimes--
+		return fmt.Errorf("mock bulk fail")
+	}
+	for _, action := range actions {
+		key := action.Index + "/" + action.ID
+		if action.Op == ESBulkOpDelete {
+			delete(w.docs, key)
+			continue
+		}
+		w.docs[key] = action.Doc
+	}
+	return nil
+}
+
+func TestESSyncer_Run(t *testing.T) {
+	ctx := context.Background()
+	table := &Table{
+		Name: "t_task",
+		Fields: []*Field{
+			{ID: 1, Name: "id", Type: 1, IsPK: true},
+			{ID: 2, Name: "create_time", Type: 5},
+			{ID: 3, Name: "ext", Type: 7},
+		},
+	}
+	table.ESConfig.Index.NameOrPrefix = "task_"
+	table.ESConfig.Index.MultiIndex = true
+	table.ESConfig.Index.IndexMode = ESIndexModeMonth
+	table.ESConfig.Index.IndexFieldID = 2
+	table.ESConfig.Sync = ESSyncModeFullAndIncr
+	createTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	rowSource := &testESSyncRowSource{}
+	for i := 1; i <= 5; i++ {
+		rowSource.rows = append(rowSource.rows, map[string]interface{}{
+			"id": i, "create_time": createTime, "ext": []byte(`{"a":1}`), "ignored": "x",
+		})
+	}
+	writer := &testESBulkWriter{failTimes: 1, docs: make(map[string]map[string]interface{})}
+	stream := NewMemoryESSyncChangeStream()
+	// 全量开始前的事件已包含在全量数据中，不会重复消费
+	stream.Push(&ESSyncChangeEvent{Table: "t_task", Type: ESSyncEventDelete, Row: map[string]interface{}{
+		"id": 2, "create_time": "2024-01-02 03:04:05"}})
+	// 全量期间发生的变更在全量完成后通过增量同步
+	rowSource.onFetch = func() {
+		stream.Push(
+			&ESSyncChangeEvent{Table: "t_task", Type: ESSyncEventDelete, Row: map[string]interface{}{
+				"id": 1, "create_time": "2024-01-02 03:04:05"}},
+			&ESSyncChangeEvent{Table: "t_other", Type: ESSyncEventInsert, Row: map[string]interface{}{"id": 1}},
+			&ESSyncChangeEvent{Table: "t_task", Type: ESSyncEventInsert, Row: map[string]interface{}{
+				"id": 6, "create_time": "2024-02-01 00:00:00", "ext": ""}},
+		)
+		stream.Close()
+	}
+	checkpointer := NewMemoryESSyncCheckpointer()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	s := NewESSyncer(d,
+		WithESSyncRowSource(rowSource),
+		WithESBulkWriter(writer),
+		WithESSyncCheckpointer(checkpointer),
+		WithESSyncRetry(ESSyncRetry{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+		WithESSyncBatch(2, 2),
+	)
+	if err := s.Run(ctx, []*Table{table}, stream); err != nil {
+		t.Fatalf("ESSyncer.Run() error = %v", err)
+	}
+	var keys []string
+	for key := range writer.docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"task_202401/2", "task_202401/3", "task_202401/4", "task_202401/5", "task_202402/6"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("ESSyncer.Run() docs = %v, want %v", keys, wantKeys)
+	}
+	wantDoc := map[string]interface{}{
+		"id": 2, "create_time": "2024-01-02 03:04:05", "ext": map[string]interface{}{"a": float64(1)}}
+	if !reflect.DeepEqual(writer.docs["task_202401/2"], wantDoc) {
+		t.Errorf("ESSyncer.Run() doc = %v, want %v", writer.docs["task_202401/2"], wantDoc)
+	}
+	checkpoint, _ := checkpointer.Load(ctx, "t_task")
+	if !checkpoint.FullLoadDone || checkpoint.LastPK != 5 || checkpoint.Position != "4" {
+		t.Errorf("ESSyncer.Run() checkpoint = %+v", checkpoint)
+	}
+	stats := s.Stats()["t_task"]
+	if stats.FullLoadRows != 5 || stats.IncrEvents != 2 || stats.Retries != 1 || stats.BulkFailures != 0 {
+		t.Errorf("ESSyncer.Run() stats = %+v", stats)
+	}
+
+	// 再次执行时全量已完成，增量从断点之后开始，不会重复写入
+	writer.docs = make(map[string]map[string]interface{})
+	if err := s.Run(ctx, []*Table{table}, stream); err != nil {
+		t.Fatalf("ESSyncer.Run() again error = %v", err)
+	}
+	if len(writer.docs) != 0 {
+		t.Errorf("ESSyncer.Run() again docs = %v, want empty", writer.docs)
+	}
+}
+
+func TestESSyncer_FileStreamDocID(t *testing.T) {
+	ctx := context.Background()
+	table := &Table{
+		Name:   "t_task",
+		Fields: []*Field{{ID: 1, Name: "id", Type: 1, IsPK: true}, {ID: 2, Name: "name", Type: 3}},
+	}
+	table.ESConfig.Index.NameOrPrefix = "task"
+	table.ESConfig.Sync = ESSyncModeFullAndIncr
+	rowSource := &testESSyncRowSource{rows: []map[string]interface{}{{"id": 1234567, "name": "a"}}}
+	writer := &testESBulkWriter{docs: make(map[string]map[string]interface{})}
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(`{"table":"t_task","type":"insert","row":{"id":1,"name":"old"}}`+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	rowSource.onFetch = func() {
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0666)
+		defer file.Close()
+		_, _ = file.WriteString(`{"table":"t_task","type":"update","row":{"id":1234567,"name":"b"}}` + "\n")
+	}
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	s := NewESSyncer(d, WithESSyncRowSource(rowSource), WithESBulkWriter(writer))
+	if err := s.Run(ctx, []*Table{table}, NewFileESSyncChangeStream(path)); err != nil {
+		t.Fatalf("ESSyncer.Run() error = %v", err)
+	}
+	if len(writer.docs) != 1 || writer.docs["task/1234567"]["name"] != "b" {
+		t.Errorf("ESSyncer.Run() docs = %v, want only task/1234567 updated by file event", writer.docs)
+	}
+}
+
+func TestESSyncer_RunIncrementalBinlogPosition(t *testing.T) {
+	ctx := context.Background()
+	table := &Table{
+		Name:   "t_task",
+		Fields: []*Field{{ID: 1, Name: "id", Type: 1, IsPK: true}},
+	}
+	table.ESConfig.Index.NameOrPrefix = "task"
+	checkpointer := NewMemoryESSyncCheckpointer()
+	if err := checkpointer.Save(ctx, &ESSyncCheckpoint{
+		Table: "t_task", FullLoadDone: true, Position: "mysql-bin.000009:999"}); err != nil {
+		t.Fatal(err)
+	}
+	stream := NewMemoryESSyncChangeStream()
+	for i, position := range []string{
+		"mysql-bin.000009:120", "mysql-bin.000009:999", "mysql-bin.000009:1234", "mysql-bin.000010:4"} {
+		stream.Push(&ESSyncChangeEvent{Table: "t_task", Type: ESSyncEventInsert,
+			Row: map[string]interface{}{"id": i + 1}, Position: position})
+	}
+	stream.Close()
+	writer := &testESBulkWriter{docs: make(map[string]map[string]interface{})}
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	s := NewESSyncer(d, WithESBulkWriter(writer), WithESSyncCheckpointer(checkpointer))
+	if err := s.RunIncremental(ctx, []*Table{table}, stream); err != nil {
+		t.Fatalf("ESSyncer.RunIncremental() error = %v", err)
+	}
+	var keys []string
+	for key := range writer.docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if want := []string{"task/3", "task/4"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("ESSyncer.RunIncremental() docs = %v, want %v", keys, want)
+	}
+	checkpoint, _ := checkpointer.Load(ctx, "t_task")
+	if checkpoint.Position != "mysql-bin.000010:4" {
+		t.Errorf("ESSyncer.RunIncremental() position = %s, want mysql-bin.000010:4", checkpoint.Position)
+	}
+}
+
+func Test_comparePosition(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "1", -1},
+		{"9", "10", -1},
+		{"10", "9", 1},
+		{"mysql-bin.000001:999", "mysql-bin.000001:1234", -1},
+		{"mysql-bin.000001:1234", "mysql-bin.000001:999", 1},
+		{"mysql-bin.000001:1234", "mysql-bin.000002:4", -1},
+		{"mysql-bin.999999:4", "mysql-bin.1000000:4", -1},
+		{"mysql-bin.000001:4", "mysql-bin.000001:4", 0},
+		{"mysql-bin.000001:4", "mysql-bin.000001:4:1", -1},
+	}
+	for _, tt := range tests {
+		got := comparePosition(tt.a, tt.b)
+		if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+			t.Errorf("comparePosition(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package metacenter
+
+import "fmt"
+
+const (
+	// ESTargetES6 ES6，使用_template接口的legacy模板，映射需要带类型名
+	ESTargetES6 = "es6"
+	// ESTargetES7 ES7，使用_index_template接口的composable模板
+	ESTargetES7 = "es7"
+	// ESTargetES8 ES8，使用_index_template接口的composable模板
+	ESTargetES8 = "es8"
+	// ESTargetOpenSearch1 OpenSearch 1.x，使用_index_template接口的composable模板，不支持unsigned_long
+	ESTargetOpenSearch1 = "opensearch1"
+	// ESTargetOpenSearch2 OpenSearch 2.x，使用_index_template接口的composable模板，
+	// unsigned_long在2.8才支持，为兼容所有2.x版本同样不使用
+	ESTargetOpenSearch2 = "opensearch2"
+)
+
+// esLegacyTypeName ES6 legacy模板中映射使用的类型名
+const esLegacyTypeName = "_doc"
+
+// ESLegacyTemplate ES6的legacy模板配置
+type ESLegacyTemplate struct {
+	IndexPatterns []string                      `json:"index_patterns"`
+	Order         int                           `json:"order"`
+	Version       int                           `json:"version"`
+	Settings      ESTemplateSettings            `json:"settings"`
+	Mappings      map[string]ESTemplateMappings `json:"mappings"`
+}
+
+type esTemplateOptions struct {
+	target string
+}
+
+// ESTemplateOption es模板生成的可选参数
+type ESTemplateOption func(*esTemplateOptions)
+
+// WithESTarget 指定es模板的目标集群，见ESTargetXXX
+func WithESTarget(target string) ESTemplateOption {
+	return func(o *esTemplateOptions) {
+		o.target = target
+	}
+}
+
+func isValidESTarget(target string) bool {
+	switch target {
+	case ESTargetES6, ESTargetES7, ESTargetES8, ESTargetOpenSearch1, ESTargetOpenSearch2:
+		return true
+	}
+	return false
+}
+
+// GetESTemplateEndpoint 获取创建es模板的请求方法以及路径
+func GetESTemplateEndpoint(target, name string) (string, string, error) {
+	switch target {
+	case ESTargetES6:
+		// 6.x的映射默认带类型名，不指定include_type_name，该参数在6.7之前的版本会被拒绝
+		return "PUT", fmt.Sprintf("/_template/%s", name), nil
+	case ESTargetES7, ESTargetES8, ESTargetOpenSearch1, ESTargetOpenSearch2:
+		return "PUT", fmt.Sprintf("/_index_template/%s", name), nil
+	}
+	return "", "", fmt.Errorf("unknown es target(%s)", target)
+}
+
+// adjustESFieldMapping 按目标集群调整字段映射，如不支持unsigned_long时退化为long
+func adjustESFieldMapping(mapping map[string]interface{}, target string) map[string]interface{} {
+	if mapping["type"] != "unsigned_long" {
+		return mapping
+	}
+	switch target {
+	case ESTargetES6, ESTargetOpenSearch1, ESTargetOpenSearch2:
+		mapping["type"] = "long"
+	}
+	return mapping
+}
+
+func toESLegacyTemplate(tpl *ESTemplate) *ESLegacyTemplate {
+	return &ESLegacyTemplate{
+		IndexPatterns: tpl.IndexPatterns,
+		Order:         tpl.Priority,
+		Version:       tpl.Version,
+		Settings:      tpl.Template.Settings,
+		Mappings: map[string]ESTemplateMappings{
+			esLegacyTypeName: tpl.Template.Mappings,
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestGetESTemplateEndpoint(t *testing.T) {
+	tests := []struct {
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{ESTargetES6, "/_template/task", false},
+		{ESTargetES7, "/_index_template/task", false},
+		{ESTargetES8, "/_index_template/task", false},
+		{ESTargetOpenSearch1, "/_index_template/task", false},
+		{ESTargetOpenSearch2, "/_index_template/task", false},
+		{"es5", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			method, path, err := GetESTemplateEndpoint(tt.target, "task")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetESTemplateEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (method != "PUT" || path != tt.want) {
+				t.Errorf("GetESTemplateEndpoint() = %v %v, want PUT %v", method, path, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_ToESTemplate_Target(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	table := newGenTestTables()[0]
+	table.ESConfig.Index.NameOrPrefix = "task_"
+	table.ESConfig.Index.MultiIndex = true
+	tests := []struct {
+		target     string
+		legacy     bool
+		wantIDType string
+	}{
+		{ESTargetES6, true, "long"},
+		{ESTargetES7, false, "unsigned_long"},
+		{ESTargetES8, false, "unsigned_long"},
+		{ESTargetOpenSearch1, false, "long"},
+		{ESTargetOpenSearch2, false, "long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			body, err := d.ToESTemplate(ctx, table, WithESTarget(tt.target))
+			if err != nil {
+				t.Fatalf("DefaultMetaCenter.ToESTemplate() error = %v", err)
+			}
+			var tpl struct {
+				IndexPatterns []string `json:"index_patterns"`
+				Order         *int     `json:"order"`
+				Mappings      map[string]struct {
+					Properties map[string]map[string]interface{} `json:"properties"`
+				} `json:"mappings"`
+				Template *struct {
+					Mappings struct {
+						Properties map[string]map[string]interface{} `json:"properties"`
+					} `json:"mappings"`
+				} `json:"template"`
+			}
+			if err := json.Unmarshal([]byte(body), &tpl); err != nil {
+				t.Fatalf("decode template error = %v", err)
+			}
+			if len(tpl.IndexPatterns) != 1 || tpl.IndexPatterns[0] != "task_*" {
+				t.Errorf("index_patterns = %v, want [task_*]", tpl.IndexPatterns)
+			}
+			var properties map[string]map[string]interface{}
+			if tt.legacy {
+				if tpl.Template != nil || tpl.Order == nil {
+					t.Fatalf("ES6 template should be legacy, got %s", body)
+				}
+				properties = tpl.Mappings[esLegacyTypeName].Properties
+			} else {
+				if tpl.Template == nil {
+					t.Fatalf("template should be composable, got %s", body)
+				}
+				properties = tpl.Template.Mappings.Properties
+			}
+			if properties["id"]["type"] != tt.wantIDType {
+				t.Errorf("id type = %v, want %v", properties["id"]["type"], tt.wantIDType)
+			}
+		})
+	}
+	if _, err := d.ToESTemplate(ctx, table, WithESTarget("es5")); err == nil {
+		t.Errorf("DefaultMetaCenter.ToESTemplate() with unknown target should return error")
+	}
+}
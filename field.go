@@ -22,6 +22,12 @@ type Field struct {
 	IsPK bool `json:"is_pk"`
 	// AutoIncr 是否自增
 	AutoIncr bool `json:"auto_incr"`
+	// Nullable 是否可为空
+	Nullable bool `json:"nullable"`
+	// Precision 小数的总位数
+	Precision int `json:"precision"`
+	// Scale 小数的小数位数
+	Scale int `json:"scale"`
 
 	Enum *Enum `json:"-"`
 }
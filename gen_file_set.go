@@ -0,0 +1,545 @@
+package metacenter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// GenerateModeWrite 写入有变更的文件
+	GenerateModeWrite = "write"
+	// GenerateModeDryRun 只列出将要新增/更新的文件，不写入
+	GenerateModeDryRun = "dry-run"
+	// GenerateModeDiff 输出与已有文件的unified diff，不写入
+	GenerateModeDiff = "diff"
+	// GenerateModeCheck 已有文件与生成结果不一致时返回ErrGeneratedFilesStale，用于CI检查
+	GenerateModeCheck = "check"
+)
+
+const (
+	// GeneratedFileCreate 文件不存在，将新增
+	GeneratedFileCreate = "create"
+	// GeneratedFileUpdate 文件存在且内容不同，将更新
+	GeneratedFileUpdate = "update"
+	// GeneratedFileUnchanged 文件存在且内容相同
+	GeneratedFileUnchanged = "unchanged"
+	// GeneratedFileRemove 之前生成的文件已不再生成，将删除
+	GeneratedFileRemove = "remove"
+)
+
+// GeneratedFileMarker 生成文件头部的标记，符合Go工具识别的"Code generated ... DO NOT EDIT."格式
+// 清理过期文件时只删除带有该标记的文件
+const GeneratedFileMarker = "Code generated by metacenter. DO NOT EDIT."
+
+// generatedHeaderCommentPrefix 文件扩展名->单行注释前缀，用于写入生成文件头部标记
+var generatedHeaderCommentPrefix = map[string]string{
+	".go":     "//",
+	".ts":     "//",
+	".js":     "//",
+	".java":   "//",
+	".kt":     "//",
+	".proto":  "//",
+	".thrift": "//",
+	".py":     "#",
+	".yaml":   "#",
+	".yml":    "#",
+	".sql":    "--",
+}
+
+// generatedRegionNote 文件包含自定义区域时写在生成文件标记之后的说明，
+// GeneratedFileMarker需要保持Go工具识别的格式，因此单独说明自定义区域可以编辑
+const generatedRegionNote = "Only the content inside metacenter:begin/end custom regions may be edited, " +
+	"it is kept on regeneration."
+
+// withGeneratedHeader 在文件内容前加上生成文件标记，包含自定义区域时再加上自定义区域可编辑的说明，
+// 扩展名不支持注释或已有标记时原样返回
+func withGeneratedHeader(ext string, content []byte) []byte {
+	prefix, ok := generatedHeaderCommentPrefix[ext]
+	if !ok || isGeneratedFile(content) {
+		return content
+	}
+	header := prefix + " " + GeneratedFileMarker + "\n"
+	if hasCustomRegion(content) {
+		header += prefix + " " + generatedRegionNote + "\n"
+	}
+	return append([]byte(header+"\n"), content...)
+}
+
+// isGeneratedFile 文件第一行是否包含生成文件标记
+func isGeneratedFile(content []byte) bool {
+	firstLine := string(content)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	return strings.Contains(firstLine, GeneratedFileMarker)
+}
+
+// ErrGeneratedFilesStale 已有的生成文件与当前生成结果不一致
+var ErrGeneratedFilesStale = errors.New("generated files are stale")
+
+// GenFS 生成文件读写使用的文件系统
+type GenFS interface {
+	// ReadFile 读取文件，文件不存在时返回的错误满足errors.Is(err, fs.ErrNotExist)
+	ReadFile(name string) ([]byte, error)
+	// WriteFile 写入文件，父目录不存在时自动创建
+	WriteFile(name string, data []byte) error
+	// RemoveFile 删除文件
+	RemoveFile(name string) error
+	// ListFiles 递归获取目录下的所有文件路径，目录不存在时返回空
+	ListFiles(dir string) ([]string, error)
+}
+
+// OSGenFS 本地文件系统
+type OSGenFS struct {
+}
+
+// NewOSGenFS 实例化本地文件系统
+func NewOSGenFS() *OSGenFS {
+	return &OSGenFS{}
+}
+
+// ReadFile 读取文件
+func (o *OSGenFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile 写入文件，父目录不存在时自动创建
+func (o *OSGenFS) WriteFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return errors.Wrapf(err, "mkdir(%s) fail", filepath.Dir(name))
+	}
+	return os.WriteFile(name, data, 0666)
+}
+
+// RemoveFile 删除文件
+func (o *OSGenFS) RemoveFile(name string) error {
+	return os.Remove(name)
+}
+
+// ListFiles 递归获取目录下的所有文件路径，目录不存在时返回空
+func (o *OSGenFS) ListFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "walk dir(%s) fail", dir)
+	}
+	return files, nil
+}
+
+// MemoryGenFS 内存文件系统，用于单元测试等不需要访问磁盘的场景
+type MemoryGenFS struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryGenFS 实例化内存文件系统，files为文件路径->内容的初始文件
+func NewMemoryGenFS(files map[string][]byte) *MemoryGenFS {
+	m := &MemoryGenFS{files: make(map[string][]byte, len(files))}
+	for name, data := range files {
+		m.files[filepath.Clean(name)] = data
+	}
+	return m
+}
+
+// ReadFile 读取文件
+func (m *MemoryGenFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile 写入文件
+func (m *MemoryGenFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+// RemoveFile 删除文件
+func (m *MemoryGenFS) RemoveFile(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// ListFiles 递归获取目录下的所有文件路径
+func (m *MemoryGenFS) ListFiles(dir string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	var files []string
+	for name := range m.files {
+		if strings.HasPrefix(name, prefix) || prefix == "."+string(filepath.Separator) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Files 获取所有文件路径->内容
+func (m *MemoryGenFS) Files() map[string][]byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	files := make(map[string][]byte, len(m.files))
+	for name, data := range m.files {
+		files[name] = append([]byte(nil), data...)
+	}
+	return files
+}
+
+// GeneratedFile 生成的文件
+type GeneratedFile struct {
+	// Path 输出文件路径
+	Path string
+	// Content 文件内容
+	Content []byte
+	// reformat 带入自定义区域的内容后重新格式化，如go文件需要补全自定义代码使用的import，old为已有文件内容
+	reformat func(content, old []byte) ([]byte, error)
+}
+
+// Hash 文件内容的sha256
+func (f *GeneratedFile) Hash() string {
+	sum := sha256.Sum256(f.Content)
+	return hex.EncodeToString(sum[:])
+}
+
+// GeneratedFileStatus 生成文件与已有文件的对比结果
+type GeneratedFileStatus struct {
+	File *GeneratedFile
+	// State 见GeneratedFileXXX，为GeneratedFileRemove时File.Content为nil
+	State string
+	// Old 已有文件内容，文件不存在时为nil
+	Old []byte
+	// Content 将写入的内容，为File.Content带入已有文件中自定义区域的内容
+	Content []byte
+	// Warnings 生成时的警告，如已有文件中的自定义区域在新内容中不存在
+	Warnings []string
+}
+
+// GeneratedFileSet 内存中的生成文件集合，按添加顺序保存，可写入、预览、对比或检查
+type GeneratedFileSet struct {
+	files []*GeneratedFile
+	index map[string]*GeneratedFile
+	fsys  GenFS
+	// cleanDirs 需要清理过期生成文件的目录
+	cleanDirs []*cleanDir
+	// warn 警告处理函数，默认输出到标准日志
+	warn func(string)
+}
+
+// NewGeneratedFileSet 实例化生成文件集合，fsys为nil时使用本地文件系统
+func NewGeneratedFileSet(fsys GenFS) *GeneratedFileSet {
+	if fsys == nil {
+		fsys = NewOSGenFS()
+	}
+	return &GeneratedFileSet{
+		index: make(map[string]*GeneratedFile),
+		fsys:  fsys,
+		warn: func(warning string) {
+			log.Printf("metacenter: %s", warning)
+		},
+	}
+}
+
+// WithWarningHandler 指定写入时的警告处理函数
+func (s *GeneratedFileSet) WithWarningHandler(warn func(string)) *GeneratedFileSet {
+	s.warn = warn
+	return s
+}
+
+// WithFS 指定读写已有文件使用的文件系统
+func (s *GeneratedFileSet) WithFS(fsys GenFS) *GeneratedFileSet {
+	s.fsys = fsys
+	return s
+}
+
+// Add 添加文件，路径重复时返回错误
+func (s *GeneratedFileSet) Add(path string, content []byte) error {
+	return s.add(&GeneratedFile{Path: path, Content: content})
+}
+
+func (s *GeneratedFileSet) add(file *GeneratedFile) error {
+	file.Path = filepath.Clean(file.Path)
+	if _, ok := s.index[file.Path]; ok {
+		return fmt.Errorf("duplicate generated file(%s)", file.Path)
+	}
+	s.files = append(s.files, file)
+	s.index[file.Path] = file
+	return nil
+}
+
+// cleanDir 需要清理过期生成文件的目录
+type cleanDir struct {
+	dir string
+	// patterns 相对dir的文件路径通配符，见filepath.Match，为空表示目录下的所有文件
+	patterns []string
+}
+
+// match 文件是否在清理范围内
+func (c *cleanDir) match(path string) bool {
+	if len(c.patterns) == 0 {
+		return true
+	}
+	rel, err := filepath.Rel(c.dir, path)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range c.patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// AddCleanDir 指定需要清理的目录，目录下带有生成文件标记、不在集合中且路径匹配patterns的文件视为过期文件
+// patterns为相对dir的文件路径通配符，见filepath.Match，如*_model.go，不指定时清理目录下的所有生成文件
+func (s *GeneratedFileSet) AddCleanDir(dir string, patterns ...string) {
+	dir = filepath.Clean(dir)
+	for _, c := range s.cleanDirs {
+		if c.dir != dir {
+			continue
+		}
+		if len(c.patterns) != 0 && len(patterns) != 0 {
+			c.patterns = append(c.patterns, patterns...)
+		} else {
+			c.patterns = nil
+		}
+		return
+	}
+	s.cleanDirs = append(s.cleanDirs, &cleanDir{dir: dir, patterns: patterns})
+}
+
+// Files 获取所有生成的文件
+func (s *GeneratedFileSet) Files() []*GeneratedFile {
+	return s.files
+}
+
+// Get 根据路径获取生成的文件，不存在时返回nil
+func (s *GeneratedFileSet) Get(path string) *GeneratedFile {
+	return s.index[filepath.Clean(path)]
+}
+
+// Status 对比生成文件与已有文件，按内容hash判断是否变更，并列出清理目录中需要删除的过期文件
+// 已有文件中自定义区域的内容会带入将写入的内容
+func (s *GeneratedFileSet) Status() ([]*GeneratedFileStatus, error) {
+	statuses := make([]*GeneratedFileStatus, 0, len(s.files))
+	for _, file := range s.files {
+		status := &GeneratedFileStatus{File: file, Content: file.Content}
+		old, err := s.fsys.ReadFile(file.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			status.State = GeneratedFileCreate
+			statuses = append(statuses, status)
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "read file(%s) fail", file.Path)
+		}
+		status.Old = old
+		if status.Content, status.Warnings, err = file.mergeCustomRegions(old); err != nil {
+			return nil, errors.Wrapf(err, "merge custom regions of file(%s) fail", file.Path)
+		}
+		status.State = GeneratedFileUpdate
+		if (&GeneratedFile{Content: old}).Hash() == (&GeneratedFile{Content: status.Content}).Hash() {
+			status.State = GeneratedFileUnchanged
+		}
+		statuses = append(statuses, status)
+	}
+	staleStatuses, err := s.staleStatuses()
+	if err != nil {
+		return nil, err
+	}
+	return append(statuses, staleStatuses...), nil
+}
+
+// mergeCustomRegions 将已有文件中自定义区域的内容带入生成的内容
+func (f *GeneratedFile) mergeCustomRegions(old []byte) ([]byte, []string, error) {
+	content, warnings, err := mergeCustomRegions(old, f.Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.reformat != nil && string(content) != string(f.Content) {
+		if content, err = f.reformat(content, old); err != nil {
+			return nil, nil, err
+		}
+	}
+	return content, warnings, nil
+}
+
+// staleStatuses 获取清理目录中带有生成文件标记但本次没有生成的文件
+// 自定义区域中有内容的过期文件不删除，通过Warnings提示手动处理
+func (s *GeneratedFileSet) staleStatuses() ([]*GeneratedFileStatus, error) {
+	var statuses []*GeneratedFileStatus
+	checked := make(map[string]bool)
+	for _, c := range s.cleanDirs {
+		paths, err := s.fsys.ListFiles(c.dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list dir(%s) fail", c.dir)
+		}
+		for _, path := range paths {
+			path = filepath.Clean(path)
+			if _, ok := s.index[path]; ok || checked[path] || !c.match(path) {
+				continue
+			}
+			checked[path] = true
+			old, err := s.fsys.ReadFile(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "read file(%s) fail", path)
+			}
+			if !isGeneratedFile(old) {
+				continue
+			}
+			if hasCustomRegionContent(old) {
+				statuses = append(statuses, &GeneratedFileStatus{
+					File:     &GeneratedFile{Path: path, Content: old},
+					State:    GeneratedFileUnchanged,
+					Old:      old,
+					Content:  old,
+					Warnings: []string{"stale generated file is kept because its custom regions are not empty"},
+				})
+				continue
+			}
+			statuses = append(statuses, &GeneratedFileStatus{
+				File:  &GeneratedFile{Path: path},
+				State: GeneratedFileRemove,
+				Old:   old,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// Write 写入新增以及有变更的文件，删除过期文件，内容未变更的文件不写入以保留修改时间
+func (s *GeneratedFileSet) Write() error {
+	statuses, err := s.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		for _, warning := range status.Warnings {
+			s.warn(fmt.Sprintf("%s: %s", status.File.Path, warning))
+		}
+		switch status.State {
+		case GeneratedFileCreate, GeneratedFileUpdate:
+			if err := s.fsys.WriteFile(status.File.Path, status.Content); err != nil {
+				return errors.Wrapf(err, "write file(%s) fail", status.File.Path)
+			}
+		case GeneratedFileRemove:
+			if err := s.fsys.RemoveFile(status.File.Path); err != nil {
+				return errors.Wrapf(err, "remove file(%s) fail", status.File.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// DryRun 输出每个文件将执行的操作，如"create  model/t_task_model.go"
+func (s *GeneratedFileSet) DryRun(w io.Writer) error {
+	statuses, err := s.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if _, err := fmt.Fprintf(w, "%-9s %s\n", status.State, status.File.Path); err != nil {
+			return errors.Wrapf(err, "write dry-run output fail")
+		}
+		for _, warning := range status.Warnings {
+			if _, err := fmt.Fprintf(w, "%-9s %s: %s\n", "warning", status.File.Path, warning); err != nil {
+				return errors.Wrapf(err, "write dry-run output fail")
+			}
+		}
+	}
+	return nil
+}
+
+// Diff 输出生成文件与已有文件的unified diff，内容相同的文件不输出
+func (s *GeneratedFileSet) Diff(w io.Writer) error {
+	statuses, err := s.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.State == GeneratedFileUnchanged {
+			continue
+		}
+		oldName, newName := "a/"+filepath.ToSlash(status.File.Path), "b/"+filepath.ToSlash(status.File.Path)
+		switch status.State {
+		case GeneratedFileCreate:
+			oldName = ""
+		case GeneratedFileRemove:
+			newName = ""
+		}
+		diff := unifiedDiff(oldName, newName, string(status.Old), string(status.Content))
+		if _, err := io.WriteString(w, diff); err != nil {
+			return errors.Wrapf(err, "write diff output fail")
+		}
+	}
+	return nil
+}
+
+// Check 检查已有文件是否与生成结果一致，不一致时返回包含文件列表的ErrGeneratedFilesStale
+func (s *GeneratedFileSet) Check() error {
+	statuses, err := s.Status()
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, status := range statuses {
+		if status.State != GeneratedFileUnchanged {
+			stale = append(stale, fmt.Sprintf("%s(%s)", status.File.Path, status.State))
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(stale)
+	return fmt.Errorf("%w: %s", ErrGeneratedFilesStale, strings.Join(stale, ", "))
+}
+
+// Apply 按mode处理生成的文件，mode见GenerateModeXXX，dry-run以及diff的结果输出到w
+func (s *GeneratedFileSet) Apply(mode string, w io.Writer) error {
+	switch mode {
+	case "", GenerateModeWrite:
+		return s.Write()
+	case GenerateModeDryRun:
+		return s.DryRun(w)
+	case GenerateModeDiff:
+		return s.Diff(w)
+	case GenerateModeCheck:
+		return s.Check()
+	}
+	return fmt.Errorf("unknown generate mode(%s)", mode)
+}
@@ -0,0 +1,616 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGeneratedFileSet_Apply(t *testing.T) {
+	newFileSet := func(fsys GenFS) *GeneratedFileSet {
+		fileSet := NewGeneratedFileSet(fsys)
+		_ = fileSet.Add("model/a.go", []byte("package model\n\nconst A = 1\n"))
+		_ = fileSet.Add("model/b.go", []byte("package model\n"))
+		_ = fileSet.Add("model/c.go", []byte("package model\n\nconst C = 3\n"))
+		return fileSet
+	}
+	existing := map[string][]byte{
+		"model/a.go": []byte("package model\n\nconst A = 0\n"),
+		"model/b.go": []byte("package model\n"),
+	}
+	tests := []struct {
+		name    string
+		mode    string
+		want    string
+		wantErr error
+	}{
+		{
+			"dry run",
+			GenerateModeDryRun,
+			"update    model/a.go\nunchanged model/b.go\ncreate    model/c.go\n",
+			nil,
+		},
+		{
+			"diff",
+			GenerateModeDiff,
+			`--- a/model/a.go
++++ b/model/a.go
+@@ -1,3 +1,3 @@
+ package model
+ 
+-const A = 0
++const A = 1
+--- /dev/null
++++ b/model/c.go
+@@ -0,0 +1,3 @@
++package model
++
++const C = 3
+`,
+			nil,
+		},
+		{
+			"check",
+			GenerateModeCheck,
+			"",
+			ErrGeneratedFilesStale,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := NewMemoryGenFS(existing)
+			out := bytes.NewBuffer(nil)
+			err := newFileSet(fsys).Apply(tt.mode, out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GeneratedFileSet.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if out.String() != tt.want {
+				t.Errorf("GeneratedFileSet.Apply() = %v, want %v", out.String(), tt.want)
+			}
+			if len(fsys.Files()) != len(existing) {
+				t.Errorf("GeneratedFileSet.Apply(%s) should not write files", tt.mode)
+			}
+		})
+	}
+
+	fsys := NewMemoryGenFS(existing)
+	if err := newFileSet(fsys).Apply(GenerateModeWrite, nil); err != nil {
+		t.Fatalf("GeneratedFileSet.Apply() error = %v", err)
+	}
+	if err := newFileSet(fsys).Check(); err != nil {
+		t.Errorf("GeneratedFileSet.Check() after write error = %v", err)
+	}
+	if err := newFileSet(fsys).Add("model/./a.go", nil); err == nil {
+		t.Errorf("GeneratedFileSet.Add() duplicate path should fail")
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	params := []*GenerateGoFilesParam{{Name: "const", OutputDirPath: "model"}}
+	fileSet, err := d.GenerateFiles(ctx, newGenTestTables(), params)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	var paths []string
+	for _, file := range fileSet.Files() {
+		paths = append(paths, file.Path)
+	}
+	if strings.Join(paths, ",") != "model/t_task_const.go,model/t_sub_task_const.go" {
+		t.Errorf("DefaultMetaCenter.GenerateFiles() paths = %v", paths)
+	}
+	fsys := NewMemoryGenFS(nil)
+	if err := fileSet.WithFS(fsys).Check(); !errors.Is(err, ErrGeneratedFilesStale) {
+		t.Errorf("GeneratedFileSet.Check() on empty fs error = %v", err)
+	}
+	if err := fileSet.Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	if !strings.Contains(string(fsys.Files()["model/t_task_const.go"]), "TableNameTTask = \"t_task\"") {
+		t.Errorf("GeneratedFileSet.Write() content = %s", fsys.Files()["model/t_task_const.go"])
+	}
+}
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{"same", "a\n", "a\n", ""},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			`--- old
++++ new
+@@ -1,5 +1,5 @@
+ 1
+-2
++x
+ 3
+ 4
+ 5
+@@ -9,4 +9,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		{
+			"missing newline",
+			"a\nb",
+			"a\nb\n",
+			`--- old
++++ new
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// countingGenFS 记录写入次数的内存文件系统
+type countingGenFS struct {
+	*MemoryGenFS
+	writes []string
+}
+
+func (c *countingGenFS) WriteFile(name string, data []byte) error {
+	c.writes = append(c.writes, name)
+	return c.MemoryGenFS.WriteFile(name, data)
+}
+
+func TestGeneratedFileSet_WriteSkipUnchangedAndRemoveStale(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	fsys := &countingGenFS{MemoryGenFS: NewMemoryGenFS(map[string][]byte{
+		"model/t_old_const.go": []byte("// " + GeneratedFileMarker + "\n\npackage model\n"),
+		"model/custom.go":      []byte("package model\n"),
+		// 其他生成器生成的文件以及子目录中的文件不匹配FileName，不清理
+		"model/t_old_model.go":     []byte("// " + GeneratedFileMarker + "\n\npackage model\n"),
+		"model/sub/t_old_const.go": []byte("// " + GeneratedFileMarker + "\n\npackage sub\n"),
+		// 自定义区域中有内容的过期文件保留并给出警告
+		"model/t_edited_const.go": []byte("// " + GeneratedFileMarker + "\n\npackage model\n\n" +
+			"// metacenter:begin custom\nconst X = 1\n// metacenter:end custom\n"),
+	})}
+	params := []*GenerateGoFilesParam{{Name: "const", OutputDirPath: "model", RemoveStale: true}}
+	generate := func() *GeneratedFileSet {
+		fileSet, err := d.GenerateFiles(ctx, newGenTestTables(), params)
+		if err != nil {
+			t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+		}
+		return fileSet.WithFS(fsys)
+	}
+
+	out := bytes.NewBuffer(nil)
+	if err := generate().DryRun(out); err != nil {
+		t.Fatalf("GeneratedFileSet.DryRun() error = %v", err)
+	}
+	want := "create    model/t_task_const.go\ncreate    model/t_sub_task_const.go\n" +
+		"unchanged model/t_edited_const.go\n" +
+		"warning   model/t_edited_const.go: stale generated file is kept because its custom regions are not empty\n" +
+		"remove    model/t_old_const.go\n"
+	if out.String() != want {
+		t.Errorf("GeneratedFileSet.DryRun() = %v, want %v", out.String(), want)
+	}
+	if err := generate().Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	files := fsys.Files()
+	if _, ok := files["model/t_old_const.go"]; ok {
+		t.Errorf("GeneratedFileSet.Write() should remove stale generated file")
+	}
+	for _, path := range []string{"model/custom.go", "model/t_old_model.go", "model/sub/t_old_const.go", "model/t_edited_const.go"} {
+		if _, ok := files[path]; !ok {
+			t.Errorf("GeneratedFileSet.Write() should keep file(%s)", path)
+		}
+	}
+	if !strings.HasPrefix(string(files["model/t_task_const.go"]), "// "+GeneratedFileMarker+"\n\n// Package model") {
+		t.Errorf("generated file should start with marker, got %s", files["model/t_task_const.go"])
+	}
+
+	fsys.writes = nil
+	if err := generate().Write(); err != nil {
+		t.Fatalf("GeneratedFileSet.Write() error = %v", err)
+	}
+	if len(fsys.writes) != 0 {
+		t.Errorf("GeneratedFileSet.Write() rewrote unchanged files %v", fsys.writes)
+	}
+	if err := generate().Check(); err != nil {
+		t.Errorf("GeneratedFileSet.Check() error = %v", err)
+	}
+}
+
+func Test_withGeneratedHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		want    string
+	}{
+		{"go", ".go", "package a\n", "// " + GeneratedFileMarker + "\n\npackage a\n"},
+		{"python", ".py", "x = 1\n", "# " + GeneratedFileMarker + "\n\nx = 1\n"},
+		{"unknown ext", ".json", "{}\n", "{}\n"},
+		{"custom region", ".go", "package a\n\n// metacenter:begin custom\n// metacenter:end custom\n",
+			"// " + GeneratedFileMarker + "\n// " + generatedRegionNote + "\n\npackage a\n\n// metacenter:begin custom\n// metacenter:end custom\n"},
+		{"already stamped", ".go", "// " + GeneratedFileMarker + "\npackage a\n", "// " + GeneratedFileMarker + "\npackage a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(withGeneratedHeader(tt.ext, []byte(tt.content))); got != tt.want {
+				t.Errorf("withGeneratedHeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_ScopeAndFileName(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name      string
+		param     *GenerateGoFilesParam
+		wantPaths []string
+		contains  map[string]string
+		wantErr   bool
+	}{
+		{
+			"schema registry",
+			&GenerateGoFilesParam{Name: "registry", Scope: GenerateScopeSchema, OutputDirPath: "model"},
+			[]string{"model/registry.go"},
+			map[string]string{"model/registry.go": "\tTableTSubTask: &TSubTask{},\n"},
+			false,
+		},
+		{
+			"per table sub package",
+			&GenerateGoFilesParam{Name: "model", FileName: "{{.Table.Name}}/model.go", OutputDirPath: "model"},
+			[]string{"model/t_task/model.go", "model/t_sub_task/model.go"},
+			map[string]string{"model/t_sub_task/model.go": "\npackage t_sub_task\n"},
+			false,
+		},
+		{
+			"schema non go file",
+			&GenerateGoFilesParam{
+				Name:          "index",
+				Scope:         GenerateScopeSchema,
+				FileName:      "migrations/{{len .Tables}}_index.txt",
+				OutputDirPath: "db",
+				TplFS: fstest.MapFS{"index.tpl": &fstest.MapFile{
+					Data: []byte("{{range .Tables}}{{.Table.Name}} {{.PkgName}}\n{{end}}"),
+				}},
+			},
+			[]string{"db/migrations/2_index.txt"},
+			map[string]string{"db/migrations/2_index.txt": "t_task migrations\nt_sub_task migrations\n"},
+			false,
+		},
+		{
+			"same file name for all tables",
+			&GenerateGoFilesParam{Name: "model", FileName: "model.go", OutputDirPath: "model"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"unknown scope",
+			&GenerateGoFilesParam{Name: "model", Scope: "db"},
+			nil,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileSet, err := d.GenerateFiles(ctx, newGenTestTables(), []*GenerateGoFilesParam{tt.param})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var paths []string
+			for _, file := range fileSet.Files() {
+				paths = append(paths, file.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("DefaultMetaCenter.GenerateFiles() paths = %v, want %v", paths, tt.wantPaths)
+			}
+			for path, want := range tt.contains {
+				if file := fileSet.Get(path); file == nil || !strings.Contains(string(file.Content), want) {
+					t.Errorf("file(%s) should contain %q, got %v", path, want, file)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_SharedEnum(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name        string
+		params      []*GenerateGoFilesParam
+		contains    map[string][]string
+		notContains map[string][]string
+	}{
+		{
+			"enum package",
+			[]*GenerateGoFilesParam{
+				{Name: "enum", Scope: GenerateScopeSchema, OutputDirPath: "model/enums"},
+				{Name: "model", OutputDirPath: "model", EnumPkg: "github.com/xxx/model/enums"},
+				{Name: "const", OutputDirPath: "model", EnumPkg: "github.com/xxx/model/enums"},
+			},
+			map[string][]string{
+				"model/enums/enum.go": {
+					"package enums\n",
+					"type TaskStatus int\n",
+					"\tTaskStatusWait TaskStatus = 1\n",
+					"\tPhaseParseFile Phase = \"parse_file\"\n",
+					"\t// Deprecated: 该枚举值已下线，仅用于兼容历史数据\n\tTaskStatusFail TaskStatus = 3\n",
+					"\t{TaskStatusFail, \"fail\", \"已失败\", true},\n",
+					"func AllTaskStatuses() []TaskStatus {",
+					"func ParseTaskStatus(s string) (TaskStatus, error) {",
+					"func (e TaskStatus) IsValid() bool {",
+					"func (e Phase) MarshalJSON() ([]byte, error) {",
+					"func (e *Phase) Scan(src interface{}) error {",
+					"func (e TaskStatus) Value() (driver.Value, error) {",
+					"\t\"database/sql/driver\"\n",
+				},
+				"model/t_task_model.go":     {"\"github.com/xxx/model/enums\"", "\tTaskStatus enums.TaskStatus "},
+				"model/t_sub_task_model.go": {"\tTaskStatus enums.TaskStatus "},
+			},
+			map[string][]string{
+				"model/t_task_const.go":     {"TaskStatusWait"},
+				"model/t_sub_task_const.go": {"TaskStatusWait"},
+			},
+		},
+		{
+			"same package",
+			[]*GenerateGoFilesParam{
+				{Name: "enum", Scope: GenerateScopeSchema, OutputDirPath: "model"},
+				{Name: "model", OutputDirPath: "model", EnumPkg: "."},
+			},
+			map[string][]string{
+				"model/enum.go":         {"package model\n", "type Phase string\n"},
+				"model/t_task_model.go": {"\tTaskStatus TaskStatus ", "\tPhase Phase "},
+			},
+			map[string][]string{
+				"model/t_task_model.go": {"import"},
+			},
+		},
+		{
+			"without enum package",
+			[]*GenerateGoFilesParam{
+				{Name: "const", OutputDirPath: "model"},
+			},
+			map[string][]string{
+				"model/t_task_const.go":     {"\tTaskStatusWait = 1\n"},
+				"model/t_sub_task_const.go": {"\tTaskStatusWait = 1\n"},
+			},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileSet, err := d.GenerateFiles(ctx, newGenTestTables(), tt.params)
+			if err != nil {
+				t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+			}
+			for path, wants := range tt.contains {
+				file := fileSet.Get(path)
+				if file == nil {
+					t.Fatalf("file(%s) not generated", path)
+				}
+				for _, want := range wants {
+					if strings.Count(string(file.Content), want) != 1 {
+						t.Errorf("file(%s) should contain %q once, got %s", path, want, file.Content)
+					}
+				}
+			}
+			for path, wants := range tt.notContains {
+				for _, want := range wants {
+					if strings.Contains(string(fileSet.Get(path).Content), want) {
+						t.Errorf("file(%s) should not contain %q", path, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_TypeScript(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tables := newGenTestTables()
+	status := tables[0].Fields[1].Enum
+	status.Values = append(status.Values, &EnumValue{ID: 6, EnumID: 1, Desc: "含\"引号\"\U0001F600", Value: "4"})
+	fileSet, err := d.GenerateFiles(ctx, tables, []*GenerateGoFilesParam{
+		{Name: "ts", Scope: GenerateScopeSchema, OutputDirPath: "web", Ext: ".ts",
+			TypeConverter: NewTypeScriptDataTypeGetter()},
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	file := fileSet.Get("web/ts.ts")
+	if file == nil {
+		t.Fatalf("file(web/ts.ts) not generated")
+	}
+	wants := []string{
+		"export const TaskStatus = {\n  // 待执行\n  Wait: 1,\n",
+		"  // 含\"引号\"\U0001F600\n  V4: 4,\n} as const;\n",
+		"export type TaskStatusValue = (typeof TaskStatus)[keyof typeof TaskStatus];\n",
+		"export const TaskStatusLabels: Record<TaskStatusValue, string> = {\n  1: \"待执行\",\n",
+		"  4: \"含\\\"引号\\\"\U0001F600\",\n",
+		"  ParseFile: \"parse_file\",\n",
+		"  \"parse_file\": \"解析文件\",\n",
+		"export interface TTask {\n  /** 自增ID */\n  id: number;\n",
+		"  phase?: PhaseValue | null;\n",
+		"  create_time: string;\n",
+		"export interface TSubTask {\n",
+		"  score?: number | null;\n",
+	}
+	for _, want := range wants {
+		if strings.Count(string(file.Content), want) != 1 {
+			t.Errorf("file(web/ts.ts) should contain %q once, got %s", want, file.Content)
+		}
+	}
+	// 共享的枚举只生成一次，两张表都引用同一个类型
+	if got := strings.Count(string(file.Content), "task_status: TaskStatusValue;\n"); got != 2 {
+		t.Errorf("file(web/ts.ts) should reference TaskStatusValue twice, got %d", got)
+	}
+}
+
+func Test_fileNamePattern(t *testing.T) {
+	tests := []struct {
+		fileName string
+		path     string
+		want     bool
+	}{
+		{"{{.Table.Name}}_model.go", "t_task_model.go", true},
+		{"{{.Table.Name}}_model.go", "t_task_const.go", false},
+		{"{{.Table.Name}}_model.go", "sub/t_task_model.go", false},
+		{"{{.Table.Name}}/{{.Table.Name}}{{.PkgName}}.go", "t_task/t_taskmodel.go", true},
+		{"registry.go", "registry.go", true},
+		{"[x]*.go", "[x]*.go", true},
+		{"[x]*.go", "x1.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fileName+" "+tt.path, func(t *testing.T) {
+			got, err := filepath.Match(fileNamePattern(tt.fileName), filepath.FromSlash(tt.path))
+			if err != nil || got != tt.want {
+				t.Errorf("fileNamePattern(%s) match %s = %v, %v, want %v", tt.fileName, tt.path, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_GenerateFiles_SharedEnumRun(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	fileSet, err := d.GenerateFiles(ctx, newGenTestTables(), []*GenerateGoFilesParam{
+		{Name: "enum", Scope: GenerateScopeSchema, OutputDirPath: "enums"},
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.GenerateFiles() error = %v", err)
+	}
+	runGeneratedGoTests(t, fileSet, map[string]string{
+		"go.mod": "module example.com/gen\n\ngo 1.17\n",
+		"enums/enum_test.go": `package enums
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type row struct {
+	Status TaskStatus ` + "`json:\"status\"`" + `
+	Phase  Phase      ` + "`json:\"phase\"`" + `
+}
+
+func TestZeroValue(t *testing.T) {
+	body, err := json.Marshal(row{})
+	if err != nil || string(body) != ` + "`" + `{"status":null,"phase":null}` + "`" + ` {
+		t.Fatalf("marshal zero = %s, %v", body, err)
+	}
+	r := row{Status: TaskStatusWait, Phase: PhaseSendFile}
+	if err := json.Unmarshal([]byte(` + "`" + `{"status":null,"phase":null}` + "`" + `), &r); err != nil || r != (row{}) {
+		t.Fatalf("unmarshal null = %+v, %v", r, err)
+	}
+	if v, err := TaskStatus(0).Value(); v != nil || err != nil {
+		t.Errorf("TaskStatus(0).Value() = %v, %v", v, err)
+	}
+	if v, err := Phase("").Value(); v != nil || err != nil {
+		t.Errorf("Phase(\"\").Value() = %v, %v", v, err)
+	}
+	status, phase := TaskStatusWait, PhaseSendFile
+	if err := status.Scan(nil); err != nil || status != 0 {
+		t.Errorf("TaskStatus.Scan(nil) = %v, %v", status, err)
+	}
+	if err := phase.Scan(nil); err != nil || phase != "" {
+		t.Errorf("Phase.Scan(nil) = %v, %v", phase, err)
+	}
+	if err := status.Scan(int64(0)); err != nil || status != 0 {
+		t.Errorf("TaskStatus.Scan(0) = %v, %v", status, err)
+	}
+}
+
+func TestKnownValue(t *testing.T) {
+	body, err := json.Marshal(row{Status: TaskStatusFail, Phase: PhaseParseFile})
+	if err != nil || string(body) != ` + "`" + `{"status":3,"phase":"parse_file"}` + "`" + ` {
+		t.Fatalf("marshal = %s, %v", body, err)
+	}
+	var r row
+	if err := json.Unmarshal([]byte(` + "`" + `{"status":"finish","phase":"send_file"}` + "`" + `), &r); err != nil ||
+		r.Status != TaskStatusFinish || r.Phase != PhaseSendFile {
+		t.Fatalf("unmarshal = %+v, %v", r, err)
+	}
+	if v, err := TaskStatusWait.Value(); v != int64(1) || err != nil {
+		t.Errorf("TaskStatusWait.Value() = %v, %v", v, err)
+	}
+	var status TaskStatus
+	if err := status.Scan(int64(3)); err != nil || status != TaskStatusFail || !status.IsDeprecated() {
+		t.Errorf("TaskStatus.Scan(3) = %v, %v", status, err)
+	}
+	if got := AllTaskStatuses(); len(got) != 2 {
+		t.Errorf("AllTaskStatuses() = %v", got)
+	}
+}
+
+// 数据库中新增的枚举值可以读取以及原样输出，通过IsValid判断
+func TestUnknownValue(t *testing.T) {
+	body, err := json.Marshal(row{Status: 9, Phase: "x"})
+	if err != nil || string(body) != ` + "`" + `{"status":9,"phase":"x"}` + "`" + ` {
+		t.Fatalf("marshal unknown = %s, %v", body, err)
+	}
+	if v, err := Phase("x").Value(); v != "x" || err != nil {
+		t.Errorf("Phase(\"x\").Value() = %v, %v", v, err)
+	}
+	var status TaskStatus
+	if err := status.Scan(int64(9)); err != nil || status != 9 || status.IsValid() {
+		t.Errorf("TaskStatus.Scan(9) = %v, %v", status, err)
+	}
+	if err := status.Scan([]byte("10")); err != nil || status != 10 || status.String() != "TaskStatus(10)" {
+		t.Errorf("TaskStatus.Scan(\"10\") = %v, %v", status, err)
+	}
+	if err := status.Scan("finish"); err != nil || status != TaskStatusFinish {
+		t.Errorf("TaskStatus.Scan(\"finish\") = %v, %v", status, err)
+	}
+	if err := status.Scan("x"); err == nil {
+		t.Errorf("TaskStatus.Scan(\"x\") should fail")
+	}
+	var phase Phase
+	if err := phase.Scan([]byte("y")); err != nil || phase != "y" || phase.IsValid() {
+		t.Errorf("Phase.Scan(\"y\") = %v, %v", phase, err)
+	}
+}
+
+// 解析外部输入时只接受已定义的枚举值
+func TestInvalidValue(t *testing.T) {
+	var r row
+	if err := json.Unmarshal([]byte(` + "`" + `{"status":9}` + "`" + `), &r); err == nil {
+		t.Errorf("unmarshal unknown TaskStatus should fail")
+	}
+	if _, err := ParsePhase("x"); err == nil {
+		t.Errorf("ParsePhase(\"x\") should fail")
+	}
+}
+`,
+	})
+}
@@ -0,0 +1,151 @@
+package metacenter
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+)
+
+// 字段的逻辑类型，屏蔽不同DataTypeGetter的类型名差异，供各语言/格式的生成器使用
+const (
+	fieldKindInt      = DataTypeInt
+	fieldKindUInt     = DataTypeUInt
+	fieldKindFloat    = DataTypeFloat
+	fieldKindDecimal  = DataTypeDecimal
+	fieldKindString   = DataTypeString
+	fieldKindDateTime = DataTypeDateTime
+	fieldKindEnum     = DataTypeEnum
+	fieldKindJSON     = DataTypeJSON
+)
+
+// getTypeKind 根据数据类型ID获取逻辑类型，兼容默认以及Golang数据类型获取器的类型名
+func (d *DefaultMetaCenter) getTypeKind(ctx context.Context, typeID int) string {
+	dataType := d.dataTypeGetter.GetByID(ctx, typeID)
+	if dataType == nil {
+		return fieldKindString
+	}
+	switch dataType.Name {
+	case DataTypeInt, "int64", "int32":
+		return fieldKindInt
+	case DataTypeUInt, "uint64", "uint32":
+		return fieldKindUInt
+	case DataTypeFloat, "float32":
+		return fieldKindFloat
+	case DataTypeDecimal, goDecimalType:
+		return fieldKindDecimal
+	case DataTypeDateTime, "utils.DateTime", "time.Time":
+		return fieldKindDateTime
+	case DataTypeEnum:
+		return fieldKindEnum
+	case DataTypeJSON:
+		return fieldKindJSON
+	}
+	return fieldKindString
+}
+
+// getFieldKind 获取字段的逻辑类型，有枚举值的字段均视为枚举
+func (d *DefaultMetaCenter) getFieldKind(ctx context.Context, field *Field) string {
+	if field.Enum != nil && len(field.Enum.Values) != 0 {
+		return fieldKindEnum
+	}
+	kind := d.getTypeKind(ctx, field.Type)
+	if kind == fieldKindEnum {
+		// 枚举类型但没有枚举值时按字符串处理
+		return fieldKindString
+	}
+	return kind
+}
+
+// isTablePK 字段是否为表的主键，与getTplParam一致，Field.IsPK或TableField.IsPrimaryKey任一标记即为主键
+func isTablePK(table *Table, field *Field) bool {
+	if field.IsPK {
+		return true
+	}
+	tableField := table.GetTableField(field.ID)
+	return tableField != nil && tableField.IsPrimaryKey == 1
+}
+
+// isNumEnum 枚举值是否为数字
+func (d *DefaultMetaCenter) isNumEnum(ctx context.Context, enum *Enum) bool {
+	kind := d.getTypeKind(ctx, enum.DataTypeID)
+	return kind == fieldKindInt || kind == fieldKindUInt
+}
+
+// genEnum 生成器使用的枚举定义，同一个Enum.ID只生成一次
+type genEnum struct {
+	// Name 枚举类型名，如TaskStatus
+	Name string
+	Enum *Enum
+	// IsNum 枚举值是否为数字
+	IsNum bool
+	// Tables 使用该枚举的表英文名
+	Tables []string
+}
+
+// collectEnums 收集表中使用到的枚举，按首次出现的顺序返回，并返回字段所属表名+字段名到枚举的映射
+// 枚举类型名取首次使用该枚举的字段名，与其他枚举重名时加上表名前缀
+// 没有Enum.ID的枚举(如由DDL解析得到)按字段各自生成
+func (d *DefaultMetaCenter) collectEnums(ctx context.Context, tables []*Table) ([]*genEnum, map[string]*genEnum) {
+	var enums []*genEnum
+	idEnums := make(map[int]*genEnum)
+	names := make(map[string]bool)
+	fieldEnums := make(map[string]*genEnum)
+	for _, table := range tables {
+		for _, field := range table.Fields {
+			if d.getFieldKind(ctx, field) != fieldKindEnum {
+				continue
+			}
+			e, ok := idEnums[field.Enum.ID]
+			if !ok || field.Enum.ID == 0 {
+				name := strcase.ToCamel(field.Name)
+				if names[name] {
+					name = strcase.ToCamel(table.Name) + name
+				}
+				names[name] = true
+				e = &genEnum{
+					Name:  name,
+					Enum:  field.Enum,
+					IsNum: d.isNumEnum(ctx, field.Enum),
+				}
+				enums = append(enums, e)
+				if field.Enum.ID != 0 {
+					idEnums[field.Enum.ID] = e
+				}
+			}
+			if len(e.Tables) == 0 || e.Tables[len(e.Tables)-1] != table.Name {
+				e.Tables = append(e.Tables, table.Name)
+			}
+			fieldEnums[table.Name+"."+field.Name] = e
+		}
+	}
+	return enums, fieldEnums
+}
+
+// enumValueName 获取枚举值名称，去掉EName中与枚举类型名重复的前缀，如TaskStatusWait->Wait
+// 去掉前缀后以数字开头时保留原名，EName为空时使用V+枚举值
+func enumValueName(enumName string, enumValue *EnumValue) string {
+	name := strcase.ToCamel(enumValue.EName)
+	trimmed := strings.TrimPrefix(name, enumName)
+	if trimmed != "" && trimmed != name && !unicode.IsDigit(rune(trimmed[0])) {
+		name = trimmed
+	}
+	if name == "" {
+		name = "V" + strcase.ToCamel(enumValue.Value)
+	}
+	return name
+}
+
+// genComment 将中文名以及备注拼接为单行注释内容
+func genComment(cname, explain string) string {
+	comment := strings.TrimSpace(cname)
+	explain = strings.TrimSpace(explain)
+	if explain != "" && explain != comment {
+		if comment != "" {
+			comment += " "
+		}
+		comment += explain
+	}
+	return strings.Join(strings.Fields(comment), " ")
+}
@@ -0,0 +1,239 @@
+package metacenter
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultGoImports 生成go文件时可自动补全的包名->导入路径
+// 如模板中使用了decimal.Decimal但没有导入时补全github.com/shopspring/decimal
+var DefaultGoImports = map[string]string{
+	"bytes":   "bytes",
+	"context": "context",
+	"decimal": "github.com/shopspring/decimal",
+	"driver":  "database/sql/driver",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"io":      "io",
+	"json":    "encoding/json",
+	"reflect": "reflect",
+	"sort":    "sort",
+	"sql":     "database/sql",
+	"strconv": "strconv",
+	"strings": "strings",
+	"testing": "testing",
+	"time":    "time",
+	// utils.DateTime为GolangDataTypeGetter中日期时间字段的类型，可通过GenerateGoFilesParam.Imports覆盖为项目自己的utils包
+	"utils": "github.com/imqishi/metacenter/utils",
+}
+
+// formatGoSource 补全缺失的import、移除未使用的已知import，并通过go/format标准化代码
+// imports为包名->导入路径，与DefaultGoImports合并且优先
+func formatGoSource(src []byte, imports map[string]string) ([]byte, error) {
+	knownImports := make(map[string]string, len(DefaultGoImports)+len(imports))
+	for name, importPath := range DefaultGoImports {
+		knownImports[name] = importPath
+	}
+	for name, importPath := range imports {
+		knownImports[name] = importPath
+	}
+	fixed, err := fixGoImports(src, knownImports)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := format.Source(fixed)
+	if err != nil {
+		return nil, withGoSourceLine(err, fixed)
+	}
+	return ret, nil
+}
+
+// goFileImports 获取go文件中的import，包名->导入路径，忽略_以及.导入，解析失败时返回空
+func goFileImports(src []byte) map[string]string {
+	imports := make(map[string]string)
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return imports
+	}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// goImport 文件中已有的import
+type goImport struct {
+	name string
+	path string
+	// spec 原始的import语句，如decimal "github.com/shopspring/decimal"
+	spec string
+}
+
+// fixGoImports 根据代码中未解析的包名引用补全import，移除未使用且路径已知的import
+func fixGoImports(src []byte, knownImports map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, withGoSourceLine(err, src)
+	}
+	knownPaths := make(map[string]string, len(knownImports))
+	for name, importPath := range knownImports {
+		knownPaths[importPath] = name
+	}
+	// 未解析的标识符作为选择器的前缀时，视为对包的引用
+	unresolved := make(map[*ast.Ident]bool, len(file.Unresolved))
+	for _, ident := range file.Unresolved {
+		unresolved[ident] = true
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && unresolved[ident] {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	var existing []*goImport
+	imported := make(map[string]bool)
+	changed := false
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		imp := &goImport{path: importPath, spec: string(src[fset.Position(spec.Pos()).Offset:fset.Position(spec.End()).Offset])}
+		knownName, known := knownPaths[importPath]
+		switch {
+		case spec.Name != nil:
+			imp.name = spec.Name.Name
+		case known:
+			imp.name = knownName
+		default:
+			imp.name = importPath[strings.LastIndex(importPath, "/")+1:]
+		}
+		// 只移除路径已知的未使用import，未知路径的包名无法可靠推断
+		if known && imp.name != "_" && imp.name != "." && !used[imp.name] {
+			changed = true
+			continue
+		}
+		imported[imp.name] = true
+		existing = append(existing, imp)
+	}
+	for name := range used {
+		if imported[name] {
+			continue
+		}
+		importPath, ok := knownImports[name]
+		if !ok {
+			continue
+		}
+		spec := strconv.Quote(importPath)
+		if importPath[strings.LastIndex(importPath, "/")+1:] != name {
+			spec = name + " " + spec
+		}
+		existing = append(existing, &goImport{name: name, path: importPath, spec: spec})
+		changed = true
+	}
+	if !changed {
+		return src, nil
+	}
+	// 删除原有的import声明，在package语句后写入重新分组排序的import
+	buf := bytes.NewBuffer(nil)
+	last := fset.Position(file.Name.End()).Offset
+	buf.Write(src[:last])
+	buf.WriteString("\n\n")
+	buf.WriteString(genGoImportDecl(existing))
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		buf.Write(src[last:fset.Position(genDecl.Pos()).Offset])
+		last = fset.Position(genDecl.End()).Offset
+	}
+	buf.Write(src[last:])
+	return buf.Bytes(), nil
+}
+
+// genGoImportDecl 生成import声明，标准库与其他包分为两组，组内按路径排序
+func genGoImportDecl(imports []*goImport) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	var std, others []*goImport
+	for _, imp := range imports {
+		if strings.Contains(strings.Split(imp.path, "/")[0], ".") {
+			others = append(others, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	buf := bytes.NewBufferString("import (\n")
+	for i, group := range [][]*goImport{std, others} {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].path < group[j].path
+		})
+		if i == 1 && len(std) != 0 && len(others) != 0 {
+			buf.WriteString("\n")
+		}
+		for _, imp := range group {
+			fmt.Fprintf(buf, "\t%s\n", imp.spec)
+		}
+	}
+	buf.WriteString(")\n")
+	return buf.String()
+}
+
+// tplErrLineRE text/template错误信息中的模板行号，如template: model:12:5: ...
+var tplErrLineRE = regexp.MustCompile(`^template: [^:]+:(\d+)`)
+
+// withTplSourceLine 在模板解析/执行错误后附加出错的模板行
+func withTplSourceLine(err error, tplBody []byte) error {
+	match := tplErrLineRE.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(match[1])
+	return withSourceLine(err, tplBody, line)
+}
+
+// withGoSourceLine 在go代码解析/格式化错误后附加出错的代码行
+func withGoSourceLine(err error, src []byte) error {
+	var pos token.Position
+	switch e := err.(type) {
+	case scanner.ErrorList:
+		if len(e) == 0 {
+			return err
+		}
+		pos = e[0].Pos
+	case *scanner.Error:
+		pos = e.Pos
+	default:
+		return err
+	}
+	return withSourceLine(err, src, pos.Line)
+}
+
+// withSourceLine 在错误后附加源码中的第line行
+func withSourceLine(err error, src []byte, line int) error {
+	lines := strings.Split(string(src), "\n")
+	if line <= 0 || line > len(lines) {
+		return err
+	}
+	return fmt.Errorf("%w\n%4d | %s", err, line, lines[line-1])
+}
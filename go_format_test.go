@@ -0,0 +1,121 @@
+package metacenter
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func Test_formatGoSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		imports map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			"add missing imports",
+			"package model\ntype T struct {\nAmount decimal.Decimal\nCreateTime utils.DateTime\nAt time.Time\n}\n",
+			map[string]string{"utils": "github.com/imqishi/utils"},
+			`package model
+
+import (
+	"time"
+
+	"github.com/imqishi/utils"
+	"github.com/shopspring/decimal"
+)
+
+type T struct {
+	Amount     decimal.Decimal
+	CreateTime utils.DateTime
+	At         time.Time
+}
+`,
+			"",
+		},
+		{
+			"default utils import",
+			"package model\ntype T struct {\nCreateTime utils.DateTime\n}\n",
+			nil,
+			`package model
+
+import (
+	"github.com/imqishi/metacenter/utils"
+)
+
+type T struct {
+	CreateTime utils.DateTime
+}
+`,
+			"",
+		},
+		{
+			"remove unused known imports and keep unknown",
+			"// Package model doc\npackage model\n\nimport (\n\"fmt\"\n\"github.com/x/y\"\n)\n\nvar _ = y.V\n",
+			nil,
+			`// Package model doc
+package model
+
+import (
+	"github.com/x/y"
+)
+
+var _ = y.V
+`,
+			"",
+		},
+		{
+			"local names are not packages",
+			"package model\nfunc f(json string) int { return len(json) }\nfunc g() {\nvar sql struct{ DB int }\n_ = sql.DB\n}\n",
+			nil,
+			`package model
+
+func f(json string) int { return len(json) }
+func g() {
+	var sql struct{ DB int }
+	_ = sql.DB
+}
+`,
+			"",
+		},
+		{
+			"syntax error shows line",
+			"package model\n\ntype T struct {\n\tA int,\n}\n",
+			nil,
+			"",
+			"   4 | \tA int,",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGoSource([]byte(tt.src), tt.imports)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("formatGoSource() error = %v, want contains %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("formatGoSource() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatGoSource() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_withTplSourceLine(t *testing.T) {
+	body := []byte("line1\n{{.Table.VarName}}\n{{.Unknown}}\n")
+	tpl := template.Must(template.New("model").Parse(string(body)))
+	err := tpl.Execute(&strings.Builder{}, &TplParam{})
+	if err == nil {
+		t.Fatalf("template execute should fail")
+	}
+	got := withTplSourceLine(err, body).Error()
+	if !strings.HasSuffix(got, "\n   3 | {{.Unknown}}") {
+		t.Errorf("withTplSourceLine() = %v", got)
+	}
+}
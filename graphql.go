@@ -0,0 +1,269 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// GraphQL自定义标量
+const (
+	GraphQLScalarDateTime = "DateTime"
+	GraphQLScalarJSON     = "JSON"
+	GraphQLScalarDecimal  = "Decimal"
+)
+
+// graphQLNameRE GraphQL名称的格式
+var graphQLNameRE = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// graphQLInvalidRE GraphQL名称中不允许出现的字符
+var graphQLInvalidRE = regexp.MustCompile(`[^_0-9A-Za-z]+`)
+
+// GraphQLParam 生成GraphQL SDL可指定的参数
+type GraphQLParam struct {
+	// WithQuery 是否生成Query类型，包含按主键查询以及分页列表查询
+	WithQuery bool
+}
+
+// ToGraphQL 将表转换为GraphQL SDL，每个表一个type，每个枚举一个enum，日期时间/JSON/小数使用自定义标量
+// 枚举值均已下线的枚举不生成enum(GraphQL的enum至少需要一个值)，字段按枚举值类型使用Int/String
+// 字段的TableField.RefTableID不为0时，为关联表生成关系字段，关联表不在tables中时忽略
+func (d *DefaultMetaCenter) ToGraphQL(ctx context.Context, tables []*Table, param *GraphQLParam) (string, error) {
+	if param == nil {
+		param = &GraphQLParam{}
+	}
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	idTables := make(map[int]*Table, len(tables))
+	for _, table := range tables {
+		idTables[table.ID] = table
+	}
+	scalars := make(map[string]bool)
+	body := bytes.NewBuffer(nil)
+	for _, table := range tables {
+		writeGraphQLDesc(body, "", genComment(table.CName, ""))
+		fmt.Fprintf(body, "type %s {\n", strcase.ToCamel(table.Name))
+		names := make(map[string]bool, len(table.Fields))
+		for _, field := range table.Fields {
+			if !graphQLNameRE.MatchString(field.Name) {
+				return "", fmt.Errorf("field(%s) of table(%s) is not a valid graphql name", field.Name, table.Name)
+			}
+			names[field.Name] = true
+			var tp string
+			switch d.getFieldKind(ctx, field) {
+			case fieldKindInt, fieldKindUInt:
+				tp = "Int"
+			case fieldKindFloat:
+				tp = "Float"
+			case fieldKindDecimal:
+				tp = GraphQLScalarDecimal
+			case fieldKindDateTime:
+				tp = GraphQLScalarDateTime
+			case fieldKindJSON:
+				tp = GraphQLScalarJSON
+			case fieldKindEnum:
+				e := fieldEnums[table.Name+"."+field.Name]
+				switch {
+				case graphQLEnumHasValue(e):
+					tp = e.Name
+				case e.IsNum:
+					tp = "Int"
+				default:
+					tp = "String"
+				}
+			default:
+				tp = "String"
+			}
+			if isTablePK(table, field) {
+				tp = "ID"
+			}
+			if tp == GraphQLScalarDecimal || tp == GraphQLScalarDateTime || tp == GraphQLScalarJSON {
+				scalars[tp] = true
+			}
+			if !field.Nullable {
+				tp += "!"
+			}
+			writeGraphQLDesc(body, "  ", genComment(field.CName, field.Explain))
+			fmt.Fprintf(body, "  %s: %s\n", field.Name, tp)
+		}
+		for _, ref := range graphQLRefs(table, idTables) {
+			name := strings.TrimPrefix(ref.table.Name, "t_")
+			if names[name] {
+				name += "_ref"
+			}
+			names[name] = true
+			writeGraphQLDesc(body, "  ", fmt.Sprintf("%s 关联字段: %s", genComment(ref.table.CName, ""),
+				strings.Join(ref.fields, ", ")))
+			fmt.Fprintf(body, "  %s: %s\n", name, strcase.ToCamel(ref.table.Name))
+		}
+		body.WriteString("}\n\n")
+	}
+	for _, e := range enums {
+		if graphQLEnumHasValue(e) {
+			writeGraphQLEnum(body, e)
+		}
+	}
+	if param.WithQuery {
+		writeGraphQLQuery(body, tables)
+	}
+	header := bytes.NewBuffer(nil)
+	scalarNames := make([]string, 0, len(scalars))
+	for scalar := range scalars {
+		scalarNames = append(scalarNames, scalar)
+	}
+	sort.Strings(scalarNames)
+	for _, scalar := range scalarNames {
+		fmt.Fprintf(header, "scalar %s\n", scalar)
+	}
+	if header.Len() != 0 {
+		header.WriteString("\n")
+	}
+	return strings.TrimRight(header.String()+body.String(), "\n") + "\n", nil
+}
+
+// graphQLRef 表的关联表以及来自关联表的字段
+type graphQLRef struct {
+	table  *Table
+	fields []string
+}
+
+// graphQLRefs 按字段顺序获取表的关联表
+func graphQLRefs(table *Table, idTables map[int]*Table) []*graphQLRef {
+	var refs []*graphQLRef
+	tableRefs := make(map[int]*graphQLRef)
+	for _, field := range table.Fields {
+		tableField := table.GetTableField(field.ID)
+		if tableField == nil || tableField.RefTableID == 0 {
+			continue
+		}
+		refTable, ok := idTables[tableField.RefTableID]
+		if !ok {
+			continue
+		}
+		ref, ok := tableRefs[refTable.ID]
+		if !ok {
+			ref = &graphQLRef{table: refTable}
+			tableRefs[refTable.ID] = ref
+			refs = append(refs, ref)
+		}
+		ref.fields = append(ref.fields, field.Name)
+	}
+	return refs
+}
+
+// graphQLEnumHasValue 枚举是否有未下线的枚举值
+func graphQLEnumHasValue(e *genEnum) bool {
+	for _, enumValue := range e.Enum.Values {
+		if !enumValue.IsOffline() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGraphQLEnum 写入枚举定义，下线的枚举值标记为@deprecated
+func writeGraphQLEnum(body *bytes.Buffer, e *genEnum) {
+	writeGraphQLDesc(body, "", genComment(e.Enum.CName, e.Enum.Explain))
+	fmt.Fprintf(body, "enum %s {\n", e.Name)
+	names := make(map[string]bool, len(e.Enum.Values))
+	for _, enumValue := range e.Enum.Values {
+		name := graphQLEnumValueName(enumValue)
+		for i := 2; names[name]; i++ {
+			name = graphQLEnumValueName(enumValue) + "_" + strconv.Itoa(i)
+		}
+		names[name] = true
+		writeGraphQLDesc(body, "  ", genComment(enumValue.Value, enumValue.Desc))
+		if enumValue.IsOffline() {
+			fmt.Fprintf(body, "  %s @deprecated(reason: \"offline\")\n", name)
+			continue
+		}
+		fmt.Fprintf(body, "  %s\n", name)
+	}
+	body.WriteString("}\n\n")
+}
+
+// graphQLEnumValueName 将EName转换为符合GraphQL命名规则的大写下划线名称
+// EName为空时使用枚举值，以数字开头时加下划线前缀，不能为true/false/null
+func graphQLEnumValueName(enumValue *EnumValue) string {
+	name := enumValue.EName
+	if strings.TrimSpace(name) == "" {
+		name = enumValue.Value
+	}
+	name = strcase.ToScreamingSnake(graphQLInvalidRE.ReplaceAllString(name, "_"))
+	name = strings.Trim(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	switch name {
+	case "TRUE", "FALSE", "NULL":
+		name = "_" + name
+	}
+	return name
+}
+
+// writeGraphQLQuery 写入Query类型，有主键的表生成按主键查询，所有表生成分页列表查询
+func writeGraphQLQuery(body *bytes.Buffer, tables []*Table) {
+	body.WriteString("type Query {\n")
+	for _, table := range tables {
+		typeName := strcase.ToCamel(table.Name)
+		name := strcase.ToLowerCamel(strings.TrimPrefix(table.Name, "t_"))
+		var args []string
+		for _, field := range table.Fields {
+			if isTablePK(table, field) {
+				args = append(args, field.Name+": ID!")
+			}
+		}
+		if len(args) != 0 {
+			writeGraphQLDesc(body, "  ", "按主键查询"+table.CName)
+			fmt.Fprintf(body, "  %s(%s): %s\n", name, strings.Join(args, ", "), typeName)
+		}
+		writeGraphQLDesc(body, "  ", "分页查询"+table.CName)
+		fmt.Fprintf(body, "  %sList(offset: Int = 0, limit: Int = 20): [%s!]!\n", name, typeName)
+	}
+	body.WriteString("}\n\n")
+}
+
+// writeGraphQLDesc 写入单行描述
+func writeGraphQLDesc(body *bytes.Buffer, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	fmt.Fprintf(body, "%s%s\n", indent, graphQLQuote(desc))
+}
+
+// graphQLQuote 按GraphQL规范转义字符串，控制字符使用\uXXXX，不会出现Go特有的\x、\U等转义
+func graphQLQuote(s string) string {
+	buf := strings.Builder{}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,159 @@
+package metacenter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToGraphQL(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	want := `scalar DateTime
+scalar JSON
+
+"任务表"
+type TTask {
+  "自增ID"
+  id: ID!
+  "任务状态"
+  task_status: TaskStatus!
+  "任务阶段"
+  phase: Phase
+  "扩展信息 json对象"
+  ext: JSON
+  "创建时间"
+  create_time: DateTime!
+}
+
+"子任务表"
+type TSubTask {
+  "自增ID"
+  id: ID!
+  "任务ID"
+  task_id: Int!
+  "任务状态"
+  task_status: TaskStatus!
+  "得分"
+  score: Float
+  "任务表 关联字段: task_id"
+  task: TTask
+}
+
+"任务状态"
+enum TaskStatus {
+  "1 待执行"
+  WAIT
+  "2 已完成"
+  FINISH
+  "3 已失败"
+  FAIL @deprecated(reason: "offline")
+}
+
+"任务阶段"
+enum Phase {
+  "parse_file 解析文件"
+  PARSE_FILE
+  "send_file 发送文件"
+  SEND_FILE
+}
+
+type Query {
+  "按主键查询任务表"
+  task(id: ID!): TTask
+  "分页查询任务表"
+  taskList(offset: Int = 0, limit: Int = 20): [TTask!]!
+  "按主键查询子任务表"
+  subTask(id: ID!): TSubTask
+  "分页查询子任务表"
+  subTaskList(offset: Int = 0, limit: Int = 20): [TSubTask!]!
+}
+`
+	got, err := d.ToGraphQL(ctx, newGenTestTables(), &GraphQLParam{WithQuery: true})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToGraphQL() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("DefaultMetaCenter.ToGraphQL() = %v, want %v", got, want)
+	}
+}
+
+func TestDefaultMetaCenter_ToGraphQL_OfflineEnumAndTablePK(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	offline := func(value string) []*EnumValue {
+		return []*EnumValue{{EName: "old", Value: value, Status: EnumValueStatusOffline}}
+	}
+	table := &Table{
+		ID:   1,
+		Name: "t_item",
+		Fields: []*Field{
+			{ID: 1, Name: "item_id", Type: 2},
+			{ID: 2, Name: "kind", Type: 6, Enum: &Enum{ID: 1, DataTypeID: 1, Values: offline("1")}},
+			{ID: 3, Name: "tag", Type: 6, Enum: &Enum{ID: 2, DataTypeID: 3, Values: offline("a")}, Nullable: true},
+		},
+		// 主键通过TableField标记
+		TableFields: map[int]*TableField{1: {TableID: 1, FieldID: 1, IsPrimaryKey: 1}},
+	}
+	want := `type TItem {
+  item_id: ID!
+  kind: Int!
+  tag: String
+}
+
+type Query {
+  "按主键查询"
+  item(item_id: ID!): TItem
+  "分页查询"
+  itemList(offset: Int = 0, limit: Int = 20): [TItem!]!
+}
+`
+	got, err := d.ToGraphQL(ctx, []*Table{table}, &GraphQLParam{WithQuery: true})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToGraphQL() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("DefaultMetaCenter.ToGraphQL() = %v, want %v", got, want)
+	}
+}
+
+func Test_graphQLEnumValueName(t *testing.T) {
+	tests := []struct {
+		name      string
+		enumValue *EnumValue
+		want      string
+	}{
+		{"snake", &EnumValue{EName: "parse_file"}, "PARSE_FILE"},
+		{"camel", &EnumValue{EName: "sendFile"}, "SEND_FILE"},
+		{"invalid char", &EnumValue{EName: "in-progress.v2"}, "IN_PROGRESS_V_2"},
+		{"digit", &EnumValue{EName: "1st"}, "_1_ST"},
+		{"empty ename", &EnumValue{Value: "3"}, "_3"},
+		{"reserved", &EnumValue{EName: "null"}, "_NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graphQLEnumValueName(tt.enumValue); got != tt.want {
+				t.Errorf("graphQLEnumValueName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_graphQLQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{"中文\U0001F600", "\"中文\U0001F600\""},
+		{`a"b\c`, `"a\"b\\c"`},
+		{"a\nb\r\tc\b\f", `"a\nb\r\tc\b\f"`},
+		{"\x00\x1f\x7f", `"\u0000\u001F\u007F"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := graphQLQuote(tt.s); got != tt.want {
+				t.Errorf("graphQLQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// JavaParam 生成Java/Kotlin文件可指定的参数
+type JavaParam struct {
+	// Package 包名，如com.example.model
+	Package string
+	// PersistencePackage JPA注解所在的包，默认为jakarta.persistence，旧版本可指定为javax.persistence
+	PersistencePackage string
+}
+
+func (p *JavaParam) fmt() *JavaParam {
+	ret := JavaParam{}
+	if p != nil {
+		ret = *p
+	}
+	if ret.PersistencePackage == "" {
+		ret.PersistencePackage = "jakarta.persistence"
+	}
+	return &ret
+}
+
+// filePath 获取类对应的文件路径，如com/example/model/TTask.java
+func (p *JavaParam) filePath(className, ext string) string {
+	if p.Package == "" {
+		return className + ext
+	}
+	return strings.ReplaceAll(p.Package, ".", "/") + "/" + className + ext
+}
+
+// javaField 生成Java/Kotlin使用的字段信息
+type javaField struct {
+	field *Field
+	// name Java属性名，驼峰形式，与Java关键字冲突时加下划线后缀
+	name string
+	// kotlinName Kotlin属性名，与Kotlin硬关键字冲突时使用反引号
+	kotlinName string
+	// javaType Java类型
+	javaType string
+	// kotlinType Kotlin类型
+	kotlinType string
+	// enum 非空表示枚举字段
+	enum *genEnum
+}
+
+// javaKeywords Java关键字以及字面量，属性名与之冲突时加下划线后缀
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "class": true, "const": true, "continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extends": true, "false": true, "final": true, "finally": true, "float": true,
+	"for": true, "goto": true, "if": true, "implements": true, "import": true, "instanceof": true, "int": true,
+	"interface": true, "long": true, "native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true, "strictfp": true,
+	"super": true, "switch": true, "synchronized": true, "this": true, "throw": true, "throws": true,
+	"transient": true, "true": true, "try": true, "void": true, "volatile": true, "while": true, "_": true,
+}
+
+// kotlinKeywords Kotlin硬关键字，属性名与之冲突时使用反引号
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true, "false": true,
+	"for": true, "fun": true, "if": true, "in": true, "interface": true, "is": true, "null": true, "object": true,
+	"package": true, "return": true, "super": true, "this": true, "throw": true, "true": true, "try": true,
+	"typealias": true, "typeof": true, "val": true, "var": true, "when": true, "while": true,
+}
+
+// javaImports 类型需要导入的包
+var javaImports = map[string]string{
+	"BigDecimal":    "java.math.BigDecimal",
+	"LocalDateTime": "java.time.LocalDateTime",
+}
+
+func (d *DefaultMetaCenter) getJavaFields(ctx context.Context, table *Table, fieldEnums map[string]*genEnum) []*javaField {
+	ret := make([]*javaField, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		name := strcase.ToLowerCamel(field.Name)
+		f := &javaField{field: field, name: name, kotlinName: name}
+		if javaKeywords[name] {
+			f.name += "_"
+		}
+		if kotlinKeywords[name] {
+			f.kotlinName = "`" + name + "`"
+		}
+		switch d.getFieldKind(ctx, field) {
+		case fieldKindInt, fieldKindUInt:
+			f.javaType, f.kotlinType = "Long", "Long"
+		case fieldKindFloat:
+			f.javaType, f.kotlinType = "Double", "Double"
+		case fieldKindDecimal:
+			f.javaType, f.kotlinType = "BigDecimal", "BigDecimal"
+		case fieldKindDateTime:
+			f.javaType, f.kotlinType = "LocalDateTime", "LocalDateTime"
+		case fieldKindEnum:
+			f.enum = fieldEnums[table.Name+"."+field.Name]
+			f.javaType, f.kotlinType = f.enum.Name, f.enum.Name
+		default:
+			// JSON字段按字符串存储
+			f.javaType, f.kotlinType = "String", "String"
+		}
+		ret = append(ret, f)
+	}
+	return ret
+}
+
+// javaDoc 转义注释内容，避免提前结束注释
+func javaDoc(comment string) string {
+	return strings.ReplaceAll(comment, "*/", "*&#47;")
+}
+
+// javaQuote 转换为Java字符串字面量
+// 控制字符使用八进制转义，Java会在词法分析前处理\uXXXX，\u000a等会变成真实的换行导致编译失败
+func javaQuote(s string) string {
+	return jvmQuote(s, false)
+}
+
+// kotlinQuote 转换为Kotlin字符串字面量，额外转义字符串模板使用的$
+func kotlinQuote(s string) string {
+	return jvmQuote(s, true)
+}
+
+func jvmQuote(s string, kotlin bool) string {
+	buf := strings.Builder{}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '$':
+			if kotlin {
+				buf.WriteString(`\$`)
+			} else {
+				buf.WriteRune(r)
+			}
+		default:
+			switch {
+			case r < 0x20 || r == 0x7f:
+				if kotlin {
+					fmt.Fprintf(&buf, `\u%04x`, r)
+				} else {
+					fmt.Fprintf(&buf, `\%03o`, r)
+				}
+			default:
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// javaEnumConst 枚举常量名，如WAIT
+func javaEnumConst(e *genEnum, enumValue *EnumValue) string {
+	return strcase.ToScreamingSnake(enumValueName(e.Name, enumValue))
+}
+
+// javaEnumCodeType 枚举code的Java类型
+func javaEnumCodeType(e *genEnum) string {
+	if e.IsNum {
+		return "Integer"
+	}
+	return "String"
+}
+
+// javaEnumCode 枚举code的字面量，quote为目标语言的字符串转义函数
+func javaEnumCode(e *genEnum, enumValue *EnumValue, quote func(string) string) (string, error) {
+	if !e.IsNum {
+		return quote(enumValue.Value), nil
+	}
+	if _, err := strconv.ParseInt(enumValue.Value, 10, 32); err != nil {
+		return "", fmt.Errorf("enum(%s) value(%s) is not a valid int", e.Name, enumValue.Value)
+	}
+	return enumValue.Value, nil
+}
+
+// javaColumnAnnotations 字段的JPA注解，quote为目标语言的字符串转义函数
+func javaColumnAnnotations(f *javaField, pkCount int, quote func(string) string) []string {
+	var ret []string
+	if f.field.IsPK {
+		ret = append(ret, "@Id")
+		if f.field.AutoIncr && pkCount == 1 {
+			ret = append(ret, "@GeneratedValue(strategy = GenerationType.IDENTITY)")
+		}
+	}
+	column := fmt.Sprintf("@Column(name = %s", quote(f.field.Name))
+	if !f.field.Nullable {
+		column += ", nullable = false"
+	}
+	ret = append(ret, column+")")
+	if f.enum != nil {
+		ret = append(ret, fmt.Sprintf("@Convert(converter = %s.Converter.class)", f.enum.Name))
+	}
+	return ret
+}
+
+func sortedImports(imports map[string]bool) []string {
+	ret := make([]string, 0, len(imports))
+	for name := range imports {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// ToJavaFiles 将表转换为JPA实体类，表使用到的枚举转换为带code/desc的Java枚举
+// 返回文件路径->文件内容，文件路径按包名组织，如com/example/model/TTask.java
+// 主键字段生成@Id，自增主键生成@GeneratedValue，联合主键通过@IdClass指定内部PK类
+func (d *DefaultMetaCenter) ToJavaFiles(ctx context.Context, tables []*Table, param *JavaParam) (map[string]string, error) {
+	param = param.fmt()
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	ret := make(map[string]string, len(tables)+len(enums))
+	for _, e := range enums {
+		body, err := javaEnumFile(e, param)
+		if err != nil {
+			return nil, err
+		}
+		ret[param.filePath(e.Name, ".java")] = body
+	}
+	for _, table := range tables {
+		className := strcase.ToCamel(table.Name)
+		ret[param.filePath(className, ".java")] = javaEntityFile(table, d.getJavaFields(ctx, table, fieldEnums), param)
+	}
+	return ret, nil
+}
+
+func javaEntityFile(table *Table, fields []*javaField, param *JavaParam) string {
+	className := strcase.ToCamel(table.Name)
+	var pkFields []*javaField
+	imports := map[string]bool{param.PersistencePackage + ".*": true}
+	for _, f := range fields {
+		if f.field.IsPK {
+			pkFields = append(pkFields, f)
+		}
+		if importPath, ok := javaImports[f.javaType]; ok {
+			imports[importPath] = true
+		}
+	}
+	if len(pkFields) > 1 {
+		imports["java.io.Serializable"] = true
+		imports["java.util.Objects"] = true
+	}
+	buf := bytes.NewBuffer(nil)
+	if param.Package != "" {
+		fmt.Fprintf(buf, "package %s;\n\n", param.Package)
+	}
+	for _, importPath := range sortedImports(imports) {
+		fmt.Fprintf(buf, "import %s;\n", importPath)
+	}
+	buf.WriteString("\n")
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "/**\n * %s\n */\n", javaDoc(comment))
+	}
+	buf.WriteString("@Entity\n")
+	fmt.Fprintf(buf, "@Table(name = %s)\n", javaQuote(table.Name))
+	if len(pkFields) > 1 {
+		fmt.Fprintf(buf, "@IdClass(%s.PK.class)\n", className)
+	}
+	fmt.Fprintf(buf, "public class %s {\n", className)
+	for _, f := range fields {
+		if comment := genComment(f.field.CName, f.field.Explain); comment != "" {
+			fmt.Fprintf(buf, "    /** %s */\n", javaDoc(comment))
+		}
+		for _, annotation := range javaColumnAnnotations(f, len(pkFields), javaQuote) {
+			fmt.Fprintf(buf, "    %s\n", annotation)
+		}
+		fmt.Fprintf(buf, "    private %s %s;\n\n", f.javaType, f.name)
+	}
+	for _, f := range fields {
+		writeJavaAccessors(buf, f)
+	}
+	if len(pkFields) > 1 {
+		writeJavaPKClass(buf, pkFields)
+	}
+	return strings.TrimRight(buf.String(), "\n") + "\n}\n"
+}
+
+func writeJavaAccessors(buf *bytes.Buffer, f *javaField) {
+	upperName := strcase.ToCamel(f.field.Name)
+	fmt.Fprintf(buf, "    public %s get%s() {\n        return %s;\n    }\n\n", f.javaType, upperName, f.name)
+	fmt.Fprintf(buf, "    public void set%s(%s %s) {\n        this.%s = %s;\n    }\n\n",
+		upperName, f.javaType, f.name, f.name, f.name)
+}
+
+// writeJavaPKClass 生成联合主键类
+func writeJavaPKClass(buf *bytes.Buffer, pkFields []*javaField) {
+	buf.WriteString("    /** 联合主键 */\n")
+	buf.WriteString("    public static class PK implements Serializable {\n")
+	names := make([]string, 0, len(pkFields))
+	equals := make([]string, 0, len(pkFields))
+	for _, f := range pkFields {
+		fmt.Fprintf(buf, "        private %s %s;\n", f.javaType, f.name)
+		names = append(names, f.name)
+		equals = append(equals, fmt.Sprintf("Objects.equals(%s, pk.%s)", f.name, f.name))
+	}
+	buf.WriteString("\n        @Override\n        public boolean equals(Object o) {\n")
+	buf.WriteString("            if (this == o) {\n                return true;\n            }\n")
+	buf.WriteString("            if (!(o instanceof PK)) {\n                return false;\n            }\n")
+	buf.WriteString("            PK pk = (PK) o;\n")
+	fmt.Fprintf(buf, "            return %s;\n        }\n", strings.Join(equals, "\n                && "))
+	buf.WriteString("\n        @Override\n        public int hashCode() {\n")
+	fmt.Fprintf(buf, "            return Objects.hash(%s);\n        }\n    }\n", strings.Join(names, ", "))
+}
+
+// javaEnumFile 生成Java枚举，包含code/desc、fromCode以及JPA的AttributeConverter
+func javaEnumFile(e *genEnum, param *JavaParam) (string, error) {
+	codeType := javaEnumCodeType(e)
+	buf := bytes.NewBuffer(nil)
+	if param.Package != "" {
+		fmt.Fprintf(buf, "package %s;\n\n", param.Package)
+	}
+	fmt.Fprintf(buf, "import %s.AttributeConverter;\n\n", param.PersistencePackage)
+	if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+		fmt.Fprintf(buf, "/**\n * %s\n */\n", javaDoc(comment))
+	}
+	fmt.Fprintf(buf, "public enum %s {\n", e.Name)
+	if len(e.Enum.Values) == 0 {
+		// 没有枚举常量时也需要分号才能声明字段以及方法
+		buf.WriteString("    ;\n")
+	}
+	for i, enumValue := range e.Enum.Values {
+		code, err := javaEnumCode(e, enumValue, javaQuote)
+		if err != nil {
+			return "", err
+		}
+		if comment := genComment(enumValue.Desc, enumValue.Explain); comment != "" {
+			fmt.Fprintf(buf, "    /** %s */\n", javaDoc(comment))
+		}
+		if enumValue.IsOffline() {
+			buf.WriteString("    @Deprecated\n")
+		}
+		sep := ","
+		if i == len(e.Enum.Values)-1 {
+			sep = ";"
+		}
+		fmt.Fprintf(buf, "    %s(%s, %s)%s\n", javaEnumConst(e, enumValue), code, javaQuote(enumValue.Desc), sep)
+	}
+	fmt.Fprintf(buf, "\n    private final %s code;\n    private final String desc;\n\n", codeType)
+	fmt.Fprintf(buf, "    %s(%s code, String desc) {\n        this.code = code;\n        this.desc = desc;\n    }\n\n", e.Name, codeType)
+	fmt.Fprintf(buf, "    public %s getCode() {\n        return code;\n    }\n\n", codeType)
+	buf.WriteString("    public String getDesc() {\n        return desc;\n    }\n\n")
+	fmt.Fprintf(buf, "    public static %s fromCode(%s code) {\n", e.Name, codeType)
+	fmt.Fprintf(buf, "        for (%s value : values()) {\n", e.Name)
+	buf.WriteString("            if (value.code.equals(code)) {\n                return value;\n            }\n        }\n")
+	buf.WriteString("        return null;\n    }\n\n")
+	buf.WriteString("    /** JPA字段转换器，数据库中存储code */\n")
+	fmt.Fprintf(buf, "    @%s.Converter\n", param.PersistencePackage)
+	fmt.Fprintf(buf, "    public static class Converter implements AttributeConverter<%s, %s> {\n", e.Name, codeType)
+	fmt.Fprintf(buf, "        @Override\n        public %s convertToDatabaseColumn(%s attribute) {\n", codeType, e.Name)
+	buf.WriteString("            return attribute == null ? null : attribute.getCode();\n        }\n\n")
+	fmt.Fprintf(buf, "        @Override\n        public %s convertToEntityAttribute(%s dbData) {\n", e.Name, codeType)
+	buf.WriteString("            return dbData == null ? null : fromCode(dbData);\n        }\n    }\n}\n")
+	return buf.String(), nil
+}
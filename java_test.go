@@ -0,0 +1,263 @@
+package metacenter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToJavaFiles(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToJavaFiles(ctx, newGenTestTables(), &JavaParam{Package: "com.example.model"})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJavaFiles() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("DefaultMetaCenter.ToJavaFiles() got %d files, want 4", len(files))
+	}
+	wants := map[string]string{
+		"com/example/model/TaskStatus.java": `package com.example.model;
+
+import jakarta.persistence.AttributeConverter;
+
+/**
+ * 任务状态
+ */
+public enum TaskStatus {
+    /** 待执行 */
+    WAIT(1, "待执行"),
+    /** 已完成 */
+    FINISH(2, "已完成"),
+    /** 已失败 */
+    @Deprecated
+    FAIL(3, "已失败");
+
+    private final Integer code;
+    private final String desc;
+
+    TaskStatus(Integer code, String desc) {
+        this.code = code;
+        this.desc = desc;
+    }
+
+    public Integer getCode() {
+        return code;
+    }
+
+    public String getDesc() {
+        return desc;
+    }
+
+    public static TaskStatus fromCode(Integer code) {
+        for (TaskStatus value : values()) {
+            if (value.code.equals(code)) {
+                return value;
+            }
+        }
+        return null;
+    }
+
+    /** JPA字段转换器，数据库中存储code */
+    @jakarta.persistence.Converter
+    public static class Converter implements AttributeConverter<TaskStatus, Integer> {
+        @Override
+        public Integer convertToDatabaseColumn(TaskStatus attribute) {
+            return attribute == null ? null : attribute.getCode();
+        }
+
+        @Override
+        public TaskStatus convertToEntityAttribute(Integer dbData) {
+            return dbData == null ? null : fromCode(dbData);
+        }
+    }
+}
+`,
+		"com/example/model/TSubTask.java": `package com.example.model;
+
+import jakarta.persistence.*;
+
+/**
+ * 子任务表
+ */
+@Entity
+@Table(name = "t_sub_task")
+public class TSubTask {
+    /** 自增ID */
+    @Id
+    @GeneratedValue(strategy = GenerationType.IDENTITY)
+    @Column(name = "id", nullable = false)
+    private Long id;
+
+    /** 任务ID */
+    @Column(name = "task_id", nullable = false)
+    private Long taskId;
+
+    /** 任务状态 */
+    @Column(name = "task_status", nullable = false)
+    @Convert(converter = TaskStatus.Converter.class)
+    private TaskStatus taskStatus;
+
+    /** 得分 */
+    @Column(name = "score")
+    private Double score;
+
+    public Long getId() {
+        return id;
+    }
+
+    public void setId(Long id) {
+        this.id = id;
+    }
+
+    public Long getTaskId() {
+        return taskId;
+    }
+
+    public void setTaskId(Long taskId) {
+        this.taskId = taskId;
+    }
+
+    public TaskStatus getTaskStatus() {
+        return taskStatus;
+    }
+
+    public void setTaskStatus(TaskStatus taskStatus) {
+        this.taskStatus = taskStatus;
+    }
+
+    public Double getScore() {
+        return score;
+    }
+
+    public void setScore(Double score) {
+        this.score = score;
+    }
+}
+`,
+	}
+	for path, want := range wants {
+		if got := files[path]; got != want {
+			t.Errorf("DefaultMetaCenter.ToJavaFiles() file(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// newJVMEnumTestTables 包含没有枚举值的枚举字段以及需要转义的枚举值
+func newJVMEnumTestTables() []*Table {
+	return []*Table{{
+		ID:   1,
+		Name: "t_item",
+		Fields: []*Field{
+			{ID: 1, Name: "id", Type: 1, IsPK: true},
+			{ID: 2, Name: "kind", Type: 6, EnumID: 1, Enum: &Enum{ID: 1, DataTypeID: 3}},
+			{ID: 3, Name: "tag", Type: 6, EnumID: 2, Enum: &Enum{ID: 2, DataTypeID: 3, Values: []*EnumValue{
+				{ID: 1, EName: "price", Desc: "价格\"$x\"\\\n\u0001", Value: "a\\u0022$b", Status: EnumValueStatusOffline},
+			}}},
+		},
+	}}
+}
+
+func TestDefaultMetaCenter_ToJavaFiles_Enum(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToJavaFiles(ctx, newJVMEnumTestTables(), nil)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJavaFiles() error = %v", err)
+	}
+	wants := map[string]string{
+		// 没有枚举值的枚举字段按字符串处理
+		"TItem.java": "    @Column(name = \"kind\", nullable = false)\n    private String kind;\n",
+		"Tag.java":   "    @Deprecated\n    PRICE(\"a\\\\u0022$b\", \"价格\\\"$x\\\"\\\\\\n\\001\");\n\n",
+	}
+	for path, want := range wants {
+		if !strings.Contains(files[path], want) {
+			t.Errorf("DefaultMetaCenter.ToJavaFiles() file(%s) = %v, want contains %v", path, files[path], want)
+		}
+	}
+}
+
+func Test_jvmQuote(t *testing.T) {
+	tests := []struct {
+		s          string
+		wantJava   string
+		wantKotlin string
+	}{
+		{"plain", `"plain"`, `"plain"`},
+		{"中文", `"中文"`, `"中文"`},
+		{`a"b\c`, `"a\"b\\c"`, `"a\"b\\c"`},
+		{"$x ${y}", `"$x ${y}"`, `"\$x \${y}"`},
+		{"a\nb\r\tc", `"a\nb\r\tc"`, `"a\nb\r\tc"`},
+		{"\x00\x1f\x7f", `"\000\037\177"`, `"\u0000\u001f\u007f"`},
+		{"\\u000a", `"\\u000a"`, `"\\u000a"`},
+		{"\U0001F600", "\"\U0001F600\"", "\"\U0001F600\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := javaQuote(tt.s); got != tt.wantJava {
+				t.Errorf("javaQuote() = %v, want %v", got, tt.wantJava)
+			}
+			if got := kotlinQuote(tt.s); got != tt.wantKotlin {
+				t.Errorf("kotlinQuote() = %v, want %v", got, tt.wantKotlin)
+			}
+		})
+	}
+}
+
+func Test_javaEnumFile_Empty(t *testing.T) {
+	e := &genEnum{Name: "Kind", Enum: &Enum{ID: 1, CName: "类型"}}
+	got, err := javaEnumFile(e, (&JavaParam{}).fmt())
+	if err != nil {
+		t.Fatalf("javaEnumFile() error = %v", err)
+	}
+	if want := "public enum Kind {\n    ;\n\n    private final String code;\n"; !strings.Contains(got, want) {
+		t.Errorf("javaEnumFile() = %v, want contains %v", got, want)
+	}
+	got, err = kotlinEnumFile(e, (&JavaParam{}).fmt())
+	if err != nil {
+		t.Fatalf("kotlinEnumFile() error = %v", err)
+	}
+	if want := "enum class Kind(val code: String, val desc: String) {\n    ;\n\n    companion object {\n"; !strings.Contains(got, want) {
+		t.Errorf("kotlinEnumFile() = %v, want contains %v", got, want)
+	}
+}
+
+// newJVMKeywordTestTables 字段名为Java/Kotlin关键字的表，id与class为联合主键
+func newJVMKeywordTestTables() []*Table {
+	return []*Table{{
+		ID:   1,
+		Name: "t_keyword",
+		Fields: []*Field{
+			{ID: 1, Name: "id", Type: 1, IsPK: true},
+			{ID: 2, Name: "class", Type: 3, IsPK: true},
+			{ID: 3, Name: "default", Type: 3},
+			{ID: 4, Name: "package", Type: 3, Nullable: true},
+			{ID: 5, Name: "enum", Type: 1},
+			{ID: 6, Name: "interface", Type: 3},
+		},
+	}}
+}
+
+func TestDefaultMetaCenter_ToJavaFiles_Keyword(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToJavaFiles(ctx, newJVMKeywordTestTables(), nil)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJavaFiles() error = %v", err)
+	}
+	content := files["TKeyword.java"]
+	for _, want := range []string{
+		"    @Column(name = \"default\", nullable = false)\n    private String default_;\n",
+		"    @Column(name = \"package\")\n    private String package_;\n",
+		"    private Long enum_;\n",
+		"    private String interface_;\n",
+		"    public String getDefault() {\n        return default_;\n    }\n",
+		"    public void setClass(String class_) {\n        this.class_ = class_;\n    }\n",
+		"        private String class_;\n",
+		"            return Objects.equals(id, pk.id)\n                && Objects.equals(class_, pk.class_);\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("DefaultMetaCenter.ToJavaFiles() = %v, want contains %v", content, want)
+		}
+	}
+}
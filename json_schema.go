@@ -0,0 +1,229 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+)
+
+// JSONSchemaDraft 生成的JSON Schema版本
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaDateTimePattern 日期时间字段的格式，对应ESDateTimeLayout
+const jsonSchemaDateTimePattern = `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`
+
+// jsonSchemaDecimalPattern 小数字段使用字符串表示以避免精度丢失
+const jsonSchemaDecimalPattern = `^-?\d+(\.\d+)?$`
+
+// jsonSchemaBuilder 生成JSON Schema，被多个字段使用的枚举放到$defs中通过$ref引用
+type jsonSchemaBuilder struct {
+	ctx        context.Context
+	center     *DefaultMetaCenter
+	fieldEnums map[string]*genEnum
+	// sharedEnums 需要放到$defs中的枚举
+	sharedEnums []*genEnum
+	isShared    map[*genEnum]bool
+	// refPrefix 共享定义的引用前缀，如#/$defs/
+	refPrefix string
+	// enumDescKey 非空时将枚举值描述以该扩展关键字输出，如OpenAPI的x-enum-descriptions
+	enumDescKey string
+}
+
+func (d *DefaultMetaCenter) newJSONSchemaBuilder(ctx context.Context, tables []*Table) *jsonSchemaBuilder {
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	refCount := make(map[*genEnum]int)
+	for _, e := range fieldEnums {
+		refCount[e]++
+	}
+	b := &jsonSchemaBuilder{
+		ctx:        ctx,
+		center:     d,
+		fieldEnums: fieldEnums,
+		isShared:   make(map[*genEnum]bool),
+		refPrefix:  "#/$defs/",
+	}
+	for _, e := range enums {
+		if refCount[e] > 1 {
+			b.sharedEnums = append(b.sharedEnums, e)
+			b.isShared[e] = true
+		}
+	}
+	return b
+}
+
+// defs 获取共享枚举的$defs定义
+func (b *jsonSchemaBuilder) defs() map[string]interface{} {
+	defs := make(map[string]interface{}, len(b.sharedEnums))
+	for _, e := range b.sharedEnums {
+		defs[e.Name] = b.enumSchema(e)
+	}
+	return defs
+}
+
+// enumSchema 枚举的schema，已下线的枚举值不允许出现；所有枚举值均已下线时保留全部枚举值并标记deprecated，
+// 避免没有enum关键字时任意值均能通过校验
+func (b *jsonSchemaBuilder) enumSchema(e *genEnum) map[string]interface{} {
+	schema := map[string]interface{}{"type": "string"}
+	if e.IsNum {
+		schema["type"] = "integer"
+	}
+	allOffline := true
+	for _, enumValue := range e.Enum.Values {
+		allOffline = allOffline && enumValue.IsOffline()
+	}
+	if allOffline {
+		schema["deprecated"] = true
+	}
+	values := make([]interface{}, 0, len(e.Enum.Values))
+	descs := make([]string, 0, len(e.Enum.Values))
+	for _, enumValue := range e.Enum.Values {
+		if enumValue.IsOffline() && !allOffline {
+			continue
+		}
+		var value interface{} = enumValue.Value
+		if e.IsNum {
+			if num, err := strconv.ParseInt(enumValue.Value, 10, 64); err == nil {
+				value = num
+			}
+		}
+		values = append(values, value)
+		descs = append(descs, enumValue.Desc)
+	}
+	schema["enum"] = values
+	if e.Enum.CName != "" {
+		schema["title"] = e.Enum.CName
+	}
+	if e.Enum.Explain != "" {
+		schema["description"] = e.Enum.Explain
+	}
+	if b.enumDescKey != "" {
+		schema[b.enumDescKey] = descs
+	}
+	return schema
+}
+
+// fieldSchema 字段的schema
+func (b *jsonSchemaBuilder) fieldSchema(table *Table, field *Field) map[string]interface{} {
+	var schema map[string]interface{}
+	switch b.center.getFieldKind(b.ctx, field) {
+	case fieldKindInt:
+		schema = map[string]interface{}{"type": "integer"}
+	case fieldKindUInt:
+		schema = map[string]interface{}{"type": "integer", "minimum": 0}
+	case fieldKindFloat:
+		schema = map[string]interface{}{"type": "number"}
+	case fieldKindDecimal:
+		schema = map[string]interface{}{"type": "string", "pattern": jsonSchemaDecimalPattern}
+	case fieldKindDateTime:
+		schema = map[string]interface{}{"type": "string", "pattern": jsonSchemaDateTimePattern}
+	case fieldKindJSON:
+		schema = map[string]interface{}{"type": []interface{}{"object", "array"}}
+	case fieldKindEnum:
+		e := b.fieldEnums[table.Name+"."+field.Name]
+		if b.isShared[e] {
+			schema = map[string]interface{}{"$ref": b.refPrefix + e.Name}
+		} else {
+			schema = b.enumSchema(e)
+		}
+	default:
+		schema = map[string]interface{}{"type": "string"}
+	}
+	if field.Nullable {
+		schema = b.nullableSchema(schema)
+	}
+	if field.CName != "" {
+		schema["title"] = field.CName
+	}
+	if field.Explain != "" {
+		schema["description"] = field.Explain
+	}
+	return schema
+}
+
+// nullableSchema 允许schema为null，enum中追加null时枚举值描述同样追加一项，保持两者一一对应
+func (b *jsonSchemaBuilder) nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	if _, ok := schema["$ref"]; ok {
+		return map[string]interface{}{
+			"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+		}
+	}
+	switch tp := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{tp, "null"}
+	case []interface{}:
+		schema["type"] = append(tp, "null")
+	}
+	if values, ok := schema["enum"].([]interface{}); ok {
+		schema["enum"] = append(values, nil)
+		if descs, ok := schema[b.enumDescKey].([]string); b.enumDescKey != "" && ok {
+			schema[b.enumDescKey] = append(descs, "null")
+		}
+	}
+	return schema
+}
+
+// tableSchema 表的schema，非空且非自增的字段为必填
+func (b *jsonSchemaBuilder) tableSchema(table *Table) map[string]interface{} {
+	properties := make(map[string]interface{}, len(table.Fields))
+	required := make([]string, 0, len(table.Fields))
+	for _, field := range table.Fields {
+		properties[field.Name] = b.fieldSchema(table, field)
+		if !field.Nullable && !field.AutoIncr {
+			required = append(required, field.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+	if table.CName != "" {
+		schema["title"] = table.CName
+	}
+	return schema
+}
+
+// ToJSONSchema 将表转换为JSON Schema(draft 2020-12)，同一枚举被多个字段使用时放到$defs中
+func (d *DefaultMetaCenter) ToJSONSchema(ctx context.Context, table *Table) (string, error) {
+	b := d.newJSONSchemaBuilder(ctx, []*Table{table})
+	schema := b.tableSchema(table)
+	schema["$schema"] = JSONSchemaDraft
+	if defs := b.defs(); len(defs) != 0 {
+		schema["$defs"] = defs
+	}
+	body, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal json schema of table(%s) fail", table.Name)
+	}
+	return string(body), nil
+}
+
+// ToJSONSchemaBundle 将多个表转换为一个JSON Schema(draft 2020-12)文档
+// 各表以驼峰表名放在$defs中，被多个表或字段使用的枚举同样放在$defs中共享，表名与枚举名冲突时返回错误
+func (d *DefaultMetaCenter) ToJSONSchemaBundle(ctx context.Context, tables []*Table) (string, error) {
+	b := d.newJSONSchemaBuilder(ctx, tables)
+	defs := b.defs()
+	for _, table := range tables {
+		name := strcase.ToCamel(table.Name)
+		if _, ok := defs[name]; ok {
+			return "", fmt.Errorf("definition name(%s) of table(%s) conflicts with another table or enum", name, table.Name)
+		}
+		defs[name] = b.tableSchema(table)
+	}
+	schema := map[string]interface{}{
+		"$schema": JSONSchemaDraft,
+		"$defs":   defs,
+	}
+	body, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal json schema bundle fail")
+	}
+	return string(body), nil
+}
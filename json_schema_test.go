@@ -0,0 +1,87 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToJSONSchemaBundle(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	got, err := d.ToJSONSchemaBundle(ctx, newGenTestTables())
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJSONSchemaBundle() error = %v", err)
+	}
+	var schema struct {
+		Schema string                            `json:"$schema"`
+		Defs   map[string]map[string]interface{} `json:"$defs"`
+	}
+	if err := json.Unmarshal([]byte(got), &schema); err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJSONSchemaBundle() invalid json = %v", err)
+	}
+	if schema.Schema != JSONSchemaDraft {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() $schema = %v", schema.Schema)
+	}
+	wantStatus := map[string]interface{}{"type": "integer", "title": "任务状态", "enum": []interface{}{float64(1), float64(2)}}
+	if !reflect.DeepEqual(schema.Defs["TaskStatus"], wantStatus) {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() TaskStatus = %v, want %v", schema.Defs["TaskStatus"], wantStatus)
+	}
+	if _, ok := schema.Defs["Phase"]; ok {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() enum used once should be inlined")
+	}
+	task := schema.Defs["TTask"]
+	wantRequired := []interface{}{"task_status", "create_time"}
+	if !reflect.DeepEqual(task["required"], wantRequired) {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() required = %v, want %v", task["required"], wantRequired)
+	}
+	properties := task["properties"].(map[string]interface{})
+	wantPhase := map[string]interface{}{
+		"type":  []interface{}{"string", "null"},
+		"title": "任务阶段",
+		"enum":  []interface{}{"parse_file", "send_file", nil},
+	}
+	if !reflect.DeepEqual(properties["phase"], wantPhase) {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() phase = %v, want %v", properties["phase"], wantPhase)
+	}
+	wantTaskStatus := map[string]interface{}{"$ref": "#/$defs/TaskStatus", "title": "任务状态"}
+	if !reflect.DeepEqual(properties["task_status"], wantTaskStatus) {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() task_status = %v, want %v", properties["task_status"], wantTaskStatus)
+	}
+}
+
+func TestDefaultMetaCenter_ToJSONSchemaBundle_Invalid(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	// 表名task_status与共享枚举TaskStatus冲突
+	tables := append(newGenTestTables(), &Table{ID: 3, Name: "task_status", Fields: []*Field{{ID: 1, Name: "id", Type: 1}}})
+	if _, err := d.ToJSONSchemaBundle(ctx, tables); err == nil {
+		t.Errorf("DefaultMetaCenter.ToJSONSchemaBundle() with conflict name should return error")
+	}
+
+	tables = newGenTestTables()
+	for _, enumValue := range tables[0].Fields[2].Enum.Values {
+		enumValue.Status = EnumValueStatusOffline
+	}
+	got, err := d.ToJSONSchema(ctx, tables[0])
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJSONSchema() error = %v", err)
+	}
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(got), &schema); err != nil {
+		t.Fatalf("DefaultMetaCenter.ToJSONSchema() invalid json = %v", err)
+	}
+	// 所有枚举值均已下线时仍限制为已有的枚举值，不能放开为任意值
+	wantPhase := map[string]interface{}{
+		"type":       []interface{}{"string", "null"},
+		"title":      "任务阶段",
+		"deprecated": true,
+		"enum":       []interface{}{"parse_file", "send_file", nil},
+	}
+	if !reflect.DeepEqual(schema.Properties["phase"], wantPhase) {
+		t.Errorf("DefaultMetaCenter.ToJSONSchema() all offline phase = %v, want %v", schema.Properties["phase"], wantPhase)
+	}
+}
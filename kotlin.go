@@ -0,0 +1,137 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// ToKotlinFiles 将表转换为JPA注解的Kotlin data class，表使用到的枚举转换为带code/desc的Kotlin枚举
+// 返回文件路径->文件内容，可为空以及自增的字段为可空类型并默认为null，
+// 其余字段为非空类型，JPA需要的无参构造函数依赖kotlin-jpa插件生成
+func (d *DefaultMetaCenter) ToKotlinFiles(ctx context.Context, tables []*Table, param *JavaParam) (map[string]string, error) {
+	param = param.fmt()
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	ret := make(map[string]string, len(tables)+len(enums))
+	for _, e := range enums {
+		body, err := kotlinEnumFile(e, param)
+		if err != nil {
+			return nil, err
+		}
+		ret[param.filePath(e.Name, ".kt")] = body
+	}
+	for _, table := range tables {
+		className := strcase.ToCamel(table.Name)
+		ret[param.filePath(className, ".kt")] = kotlinEntityFile(table, d.getJavaFields(ctx, table, fieldEnums), param)
+	}
+	return ret, nil
+}
+
+func kotlinEntityFile(table *Table, fields []*javaField, param *JavaParam) string {
+	className := strcase.ToCamel(table.Name)
+	pkCount := 0
+	imports := map[string]bool{param.PersistencePackage + ".*": true}
+	for _, f := range fields {
+		if f.field.IsPK {
+			pkCount++
+		}
+		if importPath, ok := javaImports[f.kotlinType]; ok {
+			imports[importPath] = true
+		}
+	}
+	if pkCount > 1 {
+		imports["java.io.Serializable"] = true
+	}
+	buf := bytes.NewBuffer(nil)
+	if param.Package != "" {
+		fmt.Fprintf(buf, "package %s\n\n", param.Package)
+	}
+	for _, importPath := range sortedImports(imports) {
+		fmt.Fprintf(buf, "import %s\n", importPath)
+	}
+	buf.WriteString("\n")
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "/** %s */\n", javaDoc(comment))
+	}
+	buf.WriteString("@Entity\n")
+	fmt.Fprintf(buf, "@Table(name = %s)\n", kotlinQuote(table.Name))
+	if pkCount > 1 {
+		fmt.Fprintf(buf, "@IdClass(%s.PK::class)\n", className)
+	}
+	fmt.Fprintf(buf, "data class %s(\n", className)
+	var pkProps []string
+	for _, f := range fields {
+		if comment := genComment(f.field.CName, f.field.Explain); comment != "" {
+			fmt.Fprintf(buf, "    /** %s */\n", javaDoc(comment))
+		}
+		for _, annotation := range javaColumnAnnotations(f, pkCount, kotlinQuote) {
+			annotation = strings.Replace(annotation, ".Converter.class", ".Converter::class", 1)
+			fmt.Fprintf(buf, "    %s\n", annotation)
+		}
+		prop := fmt.Sprintf("%s: %s", f.kotlinName, f.kotlinType)
+		if f.field.Nullable || f.field.AutoIncr {
+			prop += "? = null"
+		}
+		fmt.Fprintf(buf, "    var %s,\n", prop)
+		if f.field.IsPK {
+			pkProps = append(pkProps, fmt.Sprintf("var %s: %s? = null", f.kotlinName, f.kotlinType))
+		}
+	}
+	buf.WriteString(")")
+	if pkCount > 1 {
+		buf.WriteString(" {\n    /** 联合主键 */\n")
+		fmt.Fprintf(buf, "    data class PK(\n        %s,\n    ) : Serializable\n}", strings.Join(pkProps, ",\n        "))
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// kotlinEnumFile 生成Kotlin枚举，包含code/desc、fromCode以及JPA的AttributeConverter
+func kotlinEnumFile(e *genEnum, param *JavaParam) (string, error) {
+	codeType := "String"
+	if e.IsNum {
+		codeType = "Int"
+	}
+	buf := bytes.NewBuffer(nil)
+	if param.Package != "" {
+		fmt.Fprintf(buf, "package %s\n\n", param.Package)
+	}
+	fmt.Fprintf(buf, "import %s.AttributeConverter\n\n", param.PersistencePackage)
+	if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+		fmt.Fprintf(buf, "/** %s */\n", javaDoc(comment))
+	}
+	fmt.Fprintf(buf, "enum class %s(val code: %s, val desc: String) {\n", e.Name, codeType)
+	if len(e.Enum.Values) == 0 {
+		// 没有枚举常量时也需要分号才能声明companion object等成员
+		buf.WriteString("    ;\n")
+	}
+	for i, enumValue := range e.Enum.Values {
+		code, err := javaEnumCode(e, enumValue, kotlinQuote)
+		if err != nil {
+			return "", err
+		}
+		if comment := genComment(enumValue.Desc, enumValue.Explain); comment != "" {
+			fmt.Fprintf(buf, "    /** %s */\n", javaDoc(comment))
+		}
+		if enumValue.IsOffline() {
+			buf.WriteString("    @Deprecated(\"已下线\")\n")
+		}
+		sep := ","
+		if i == len(e.Enum.Values)-1 {
+			sep = ";"
+		}
+		fmt.Fprintf(buf, "    %s(%s, %s)%s\n", javaEnumConst(e, enumValue), code, kotlinQuote(enumValue.Desc), sep)
+	}
+	buf.WriteString("\n    companion object {\n")
+	fmt.Fprintf(buf, "        fun fromCode(code: %s): %s? = values().firstOrNull { it.code == code }\n    }\n\n", codeType, e.Name)
+	buf.WriteString("    /** JPA字段转换器，数据库中存储code */\n")
+	fmt.Fprintf(buf, "    @%s.Converter\n", param.PersistencePackage)
+	fmt.Fprintf(buf, "    class Converter : AttributeConverter<%s?, %s?> {\n", e.Name, codeType)
+	fmt.Fprintf(buf, "        override fun convertToDatabaseColumn(attribute: %s?): %s? = attribute?.code\n\n", e.Name, codeType)
+	fmt.Fprintf(buf, "        override fun convertToEntityAttribute(dbData: %s?): %s? = dbData?.let { fromCode(it) }\n", codeType, e.Name)
+	buf.WriteString("    }\n}\n")
+	return buf.String(), nil
+}
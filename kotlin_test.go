@@ -0,0 +1,122 @@
+package metacenter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToKotlinFiles(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToKotlinFiles(ctx, newGenTestTables(), &JavaParam{Package: "com.example.model"})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToKotlinFiles() error = %v", err)
+	}
+	if len(files) != 4 {
+		t.Errorf("DefaultMetaCenter.ToKotlinFiles() got %d files, want 4", len(files))
+	}
+	wants := map[string]string{
+		"com/example/model/Phase.kt": `package com.example.model
+
+import jakarta.persistence.AttributeConverter
+
+/** 任务阶段 */
+enum class Phase(val code: String, val desc: String) {
+    /** 解析文件 */
+    PARSE_FILE("parse_file", "解析文件"),
+    /** 发送文件 */
+    SEND_FILE("send_file", "发送文件");
+
+    companion object {
+        fun fromCode(code: String): Phase? = values().firstOrNull { it.code == code }
+    }
+
+    /** JPA字段转换器，数据库中存储code */
+    @jakarta.persistence.Converter
+    class Converter : AttributeConverter<Phase?, String?> {
+        override fun convertToDatabaseColumn(attribute: Phase?): String? = attribute?.code
+
+        override fun convertToEntityAttribute(dbData: String?): Phase? = dbData?.let { fromCode(it) }
+    }
+}
+`,
+		"com/example/model/TTask.kt": `package com.example.model
+
+import jakarta.persistence.*
+import java.time.LocalDateTime
+
+/** 任务表 */
+@Entity
+@Table(name = "t_task")
+data class TTask(
+    /** 自增ID */
+    @Id
+    @GeneratedValue(strategy = GenerationType.IDENTITY)
+    @Column(name = "id", nullable = false)
+    var id: Long? = null,
+    /** 任务状态 */
+    @Column(name = "task_status", nullable = false)
+    @Convert(converter = TaskStatus.Converter::class)
+    var taskStatus: TaskStatus,
+    /** 任务阶段 */
+    @Column(name = "phase")
+    @Convert(converter = Phase.Converter::class)
+    var phase: Phase? = null,
+    /** 扩展信息 json对象 */
+    @Column(name = "ext")
+    var ext: String? = null,
+    /** 创建时间 */
+    @Column(name = "create_time", nullable = false)
+    var createTime: LocalDateTime,
+)
+`,
+	}
+	for path, want := range wants {
+		if got := files[path]; got != want {
+			t.Errorf("DefaultMetaCenter.ToKotlinFiles() file(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestDefaultMetaCenter_ToKotlinFiles_Enum(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToKotlinFiles(ctx, newJVMEnumTestTables(), nil)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToKotlinFiles() error = %v", err)
+	}
+	wants := map[string]string{
+		// 没有枚举值的枚举字段按字符串处理
+		"TItem.kt": "    @Column(name = \"kind\", nullable = false)\n    var kind: String,\n",
+		"Tag.kt":   "    @Deprecated(\"已下线\")\n    PRICE(\"a\\\\u0022\\$b\", \"价格\\\"\\$x\\\"\\\\\\n\\u0001\");\n\n",
+	}
+	for path, want := range wants {
+		if !strings.Contains(files[path], want) {
+			t.Errorf("DefaultMetaCenter.ToKotlinFiles() file(%s) = %v, want contains %v", path, files[path], want)
+		}
+	}
+}
+
+func TestDefaultMetaCenter_ToKotlinFiles_Keyword(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	files, err := d.ToKotlinFiles(ctx, newJVMKeywordTestTables(), nil)
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToKotlinFiles() error = %v", err)
+	}
+	content := files["TKeyword.kt"]
+	for _, want := range []string{
+		"    @Column(name = \"class\", nullable = false)\n    var `class`: String,\n",
+		// default以及enum不是Kotlin的硬关键字，可以直接作为属性名
+		"    @Column(name = \"default\", nullable = false)\n    var default: String,\n",
+		"    var `package`: String? = null,\n",
+		"    var enum: Long,\n",
+		"    var `interface`: String,\n",
+		"        var id: Long? = null,\n        var `class`: String? = null,\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("DefaultMetaCenter.ToKotlinFiles() = %v, want contains %v", content, want)
+		}
+	}
+}
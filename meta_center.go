@@ -5,11 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
+	"io/fs"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -20,16 +20,53 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	// GenerateScopeTable 表级模板，每个表生成一个文件，模板参数为*TplParam
+	GenerateScopeTable = "table"
+	// GenerateScopeSchema schema级模板，所有表生成一个文件，模板参数为*TplSchemaParam
+	GenerateScopeSchema = "schema"
+)
+
 // GenerateGoFilesParam 生成Go模板文件可指定的参数
 type GenerateGoFilesParam struct {
 	// Name 输出文件类型，如model/dao/const...
 	Name string
-	// TplFilePath 模板文件路径
+	// TplFilePath 模板文件路径，指定时优先于TplName以及TplFS
 	TplFilePath string
+	// TplName 模板名，如model/const/ts，默认与Name相同，从TplFS中读取TplName+.tpl
+	TplName string
+	// TplFS 模板所在的文件系统，默认为内置模板DefaultTplFS()，可传入embed.FS等自定义模板包
+	TplFS fs.FS
 	// OutputDirPath 输出文件夹路径
 	OutputDirPath string
 	// InjectParams 注入任意额外参数
 	InjectParams map[string]string
+	// Ext 输出文件扩展名，默认为.go，用于默认的FileName
+	Ext string
+	// Scope 模板作用范围，见GenerateScopeXXX，默认每个表生成一个文件
+	Scope string
+	// FileName 输出文件名模板，相对于OutputDirPath，可包含子目录，模板参数与内容模板相同，
+	// 如{{.Table.Name}}/model.go，包名为文件所在文件夹名；只有.go文件会补全import并通过go/format标准化
+	// 默认表级为{表英文名}_{Name}{Ext}，schema级为{Name}{Ext}
+	FileName string
+	// Imports 包名->导入路径，补充或覆盖DefaultGoImports，如utils->github.com/xxx/utils
+	Imports map[string]string
+	// NoGeneratedHeader 不在文件头部写入GeneratedFileMarker标记
+	NoGeneratedHeader bool
+	// Funcs 自定义模板函数，与内置模板函数同名时覆盖内置函数，内置函数见readme
+	Funcs template.FuncMap
+	// RemoveStale 删除OutputDirPath中带有生成文件标记、路径匹配FileName但本次没有生成的文件，如已删除的表对应的文件，
+	// 自定义区域中有内容的文件不删除，只给出警告
+	RemoveStale bool
+	// EnumPkg 共享枚举包的导入路径，如github.com/xxx/model/enums，为"."时表示与生成文件同包
+	// 指定时枚举字段类型为共享枚举包中的命名类型，如enums.TaskStatus，const模板不再按表重复生成枚举常量，
+	// 共享枚举包通过Name为enum、Scope为schema的模板生成，同一个Enum.ID只生成一次
+	EnumPkg string
+	// ModelPkg 模型包的导入路径，如github.com/xxx/model，用于dao等引用模型的模板，为空时表示与生成文件同包
+	ModelPkg string
+	// TypeConverter 目标语言的类型获取器，非空时字段类型通过其GetByName按字段逻辑类型(DataTypeXXX)获取，
+	// 如生成TypeScript文件时使用TypeScriptDataTypeGetter
+	TypeConverter DataTypeGetter
 }
 
 // Fmt 格式化参数
@@ -43,6 +80,38 @@ func (p *GenerateGoFilesParam) Fmt() error {
 	if p.OutputDirPath[len(p.OutputDirPath)-1] == '/' {
 		p.OutputDirPath = p.OutputDirPath[:len(p.OutputDirPath)-1]
 	}
+	if p.Ext == "" {
+		p.Ext = ".go"
+	}
+	if p.Ext[0] != '.' {
+		p.Ext = "." + p.Ext
+	}
+	switch p.Scope {
+	case "":
+		p.Scope = GenerateScopeTable
+	case GenerateScopeTable, GenerateScopeSchema:
+	default:
+		return fmt.Errorf("unknown param Scope(%s)", p.Scope)
+	}
+	if (p.EnumPkg != "" && p.EnumPkg != ".") || p.ModelPkg != "" {
+		// 复制一份避免修改调用方的Imports
+		imports := make(map[string]string, len(p.Imports)+2)
+		for _, importPath := range []string{p.EnumPkg, p.ModelPkg} {
+			if importPath != "" && importPath != "." {
+				imports[path.Base(importPath)] = importPath
+			}
+		}
+		for name, importPath := range p.Imports {
+			imports[name] = importPath
+		}
+		p.Imports = imports
+	}
+	if p.FileName == "" {
+		p.FileName = p.Name + p.Ext
+		if p.Scope == GenerateScopeTable {
+			p.FileName = "{{.Table.Name}}_" + p.FileName
+		}
+	}
 	return nil
 }
 
@@ -54,12 +123,38 @@ type MetaCenter interface {
 	GetTableByID(ctx context.Context, id int) *Table
 	// GetAllTables 获取所有表配置
 	GetAllTables(ctx context.Context) []*Table
-	// GenerateGoFiles 生成go文件
-	GenerateGoFiles(ctx context.Context, params []*GenerateGoFilesParam) error
+	// GenerateGoFiles 生成go文件并写入OutputDirPath
+	GenerateGoFiles(ctx context.Context, tables []*Table, params []*GenerateGoFilesParam) error
+	// GenerateFiles 在内存中生成文件
+	GenerateFiles(ctx context.Context, tables []*Table, params []*GenerateGoFilesParam) (*GeneratedFileSet, error)
 	// ParseFromMySQLDDL 将MySQL-DDL语句转化为定义的meta结构
 	ParseFromMySQLDDL(ctx context.Context, ddl string) (*Table, error)
 	// ToESTemplate 将Table转换为es模板
-	ToESTemplate(ctx context.Context, table *Table) (string, error)
+	ToESTemplate(ctx context.Context, table *Table, opts ...ESTemplateOption) (string, error)
+	// ToProto 将表以及表使用到的枚举转换为proto3定义
+	ToProto(ctx context.Context, tables []*Table, param *ProtoParam) (string, error)
+	// ToJSONSchema 将表转换为JSON Schema
+	ToJSONSchema(ctx context.Context, table *Table) (string, error)
+	// ToJSONSchemaBundle 将多个表转换为一个共享枚举定义的JSON Schema
+	ToJSONSchemaBundle(ctx context.Context, tables []*Table) (string, error)
+	// ToOpenAPI 将表转换为OpenAPI 3文档
+	ToOpenAPI(ctx context.Context, tables []*Table, param *OpenAPIParam) (string, error)
+	// ToJavaFiles 将表转换为JPA实体类以及Java枚举
+	ToJavaFiles(ctx context.Context, tables []*Table, param *JavaParam) (map[string]string, error)
+	// ToKotlinFiles 将表转换为JPA注解的Kotlin data class以及Kotlin枚举
+	ToKotlinFiles(ctx context.Context, tables []*Table, param *JavaParam) (map[string]string, error)
+	// ToPython 将表转换为Python的pydantic模型或dataclass
+	ToPython(ctx context.Context, tables []*Table, param *PythonParam) (string, error)
+	// ToAvroSchema 将表转换为avro record schema
+	ToAvroSchema(ctx context.Context, table *Table, param *AvroParam) (string, error)
+	// ToGraphQL 将表转换为GraphQL SDL
+	ToGraphQL(ctx context.Context, tables []*Table, param *GraphQLParam) (string, error)
+	// ToDataDictionary 将表导出为Markdown/HTML/CSV格式的数据字典
+	ToDataDictionary(ctx context.Context, tables []*Table, param *DataDictParam) (string, error)
+	// ToERDiagram 根据表的关联生成Mermaid/PlantUML/DOT格式的ER图
+	ToERDiagram(ctx context.Context, tables []*Table, param *ERDiagramParam) (string, error)
+	// ToThrift 将表以及表使用到的枚举转换为thrift定义
+	ToThrift(ctx context.Context, tables []*Table, param *ThriftParam) (string, error)
 }
 
 // DefaultMetaCenter 默认实现
@@ -138,17 +233,18 @@ func (d *DefaultMetaCenter) getTableFields(ctx context.Context, table *Table) *T
 	}
 	table.NameFields = make(map[string]*Field)
 	tableFields := d.tableFieldGetter.GetFields(ctx, table.ID)
+	table.TableFields = tableFields
+	// 按字段ID排序，保证字段顺序以及生成的文件内容稳定
+	fieldIDs := make([]int, 0, len(tableFields))
 	for fieldID := range tableFields {
+		fieldIDs = append(fieldIDs, fieldID)
+	}
+	sort.Ints(fieldIDs)
+	for _, fieldID := range fieldIDs {
 		field := d.fieldGetter.GetByID(ctx, fieldID)
-		enum := d.enumGetter.GetByID(ctx, field.EnumID)
-		if enum != nil {
-			enum.Value2Values = make(map[string]*EnumValue)
-			enumValues := d.enumValueGetter.FindByEnumID(ctx, enum.ID)
-			for _, enumValue := range enumValues {
-				enum.Values = append(enum.Values, enumValue)
-				enum.Value2Values[enumValue.Value] = enumValue
-			}
-			field.Enum = enum
+		// 只有指定了枚举的字段才获取枚举配置
+		if field.EnumID != 0 {
+			field.Enum = d.getEnum(ctx, field.EnumID)
 		}
 		table.Fields = append(table.Fields, field)
 		table.NameFields[field.Name] = field
@@ -156,6 +252,20 @@ func (d *DefaultMetaCenter) getTableFields(ctx context.Context, table *Table) *T
 	return table
 }
 
+func (d *DefaultMetaCenter) getEnum(ctx context.Context, enumID int) *Enum {
+	enum := d.enumGetter.GetByID(ctx, enumID)
+	if enum == nil {
+		return nil
+	}
+	enum.Value2Values = make(map[string]*EnumValue)
+	enumValues := d.enumValueGetter.FindByEnumID(ctx, enum.ID)
+	for _, enumValue := range enumValues {
+		enum.Values = append(enum.Values, enumValue)
+		enum.Value2Values[enumValue.Value] = enumValue
+	}
+	return enum
+}
+
 // GetTableByID 根据表ID获取配置
 func (d *DefaultMetaCenter) GetTableByID(ctx context.Context, id int) *Table {
 	table := d.tableGetter.GetByID(ctx, id)
@@ -172,86 +282,257 @@ func (d *DefaultMetaCenter) GetAllTables(ctx context.Context) []*Table {
 	return ret
 }
 
-// GenerateGoFiles 生成go文件
+// GenerateGoFiles 生成go文件并写入OutputDirPath
 func (d *DefaultMetaCenter) GenerateGoFiles(ctx context.Context, tables []*Table, params []*GenerateGoFilesParam) error {
+	fileSet, err := d.GenerateFiles(ctx, tables, params)
+	if err != nil {
+		return err
+	}
+	return fileSet.Write()
+}
+
+// GenerateFiles 在内存中生成文件，不访问输出目录，可通过返回的文件集合写入、预览、对比或检查
+func (d *DefaultMetaCenter) GenerateFiles(ctx context.Context, tables []*Table,
+	params []*GenerateGoFilesParam) (*GeneratedFileSet, error) {
+	fileSet := NewGeneratedFileSet(nil)
 	for _, param := range params {
 		if err := param.Fmt(); err != nil {
-			return errors.Wrapf(err, "param check fail")
+			return nil, errors.Wrapf(err, "param check fail")
+		}
+		if param.RemoveStale {
+			// 只清理本次FileName能生成的文件，避免删除同一目录下其他生成器的文件
+			fileSet.AddCleanDir(param.OutputDirPath, fileNamePattern(param.FileName))
 		}
-		tplFileBody, err := os.ReadFile(param.TplFilePath)
+		tplSource, tplFileBody, err := param.readTpl()
 		if err != nil {
-			return errors.Wrapf(err, "read TplFilePath(%s) fail", param.TplFilePath)
+			return nil, err
 		}
-		tpl := template.Must(template.New(param.Name).Parse(string(tplFileBody)))
-		for _, table := range tables {
-			tplParam := d.getTplParam(ctx, table, param)
-			if err := os.MkdirAll(param.OutputDirPath, 0777); err != nil {
-				return errors.Wrapf(err, "mkdir(%s) fail", param.OutputDirPath)
+		funcs := d.tplFuncMap(ctx, param)
+		tpl, err := template.New(param.Name).Funcs(funcs).Parse(string(tplFileBody))
+		if err != nil {
+			return nil, errors.Wrapf(withTplSourceLine(err, tplFileBody), "parse tpl(%s) fail", tplSource)
+		}
+		fileNameTpl, err := template.New(param.Name + ".FileName").Funcs(funcs).Parse(param.FileName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse FileName(%s) fail", param.FileName)
+		}
+		enums, fieldEnums := d.collectEnums(ctx, tables)
+		render := func(data interface{}, setPkgName func(string)) error {
+			fileName := bytes.NewBuffer(nil)
+			if err := fileNameTpl.Execute(fileName, data); err != nil {
+				return errors.Wrapf(err, "execute FileName(%s) fail", param.FileName)
 			}
-			filePath := path.Join(param.OutputDirPath, fmt.Sprintf("%s_%s.go", table.Name, param.Name))
-			filePath, err := filepath.Abs(filePath)
-			if err != nil {
-				return errors.Wrapf(err, "create file abs path(%s) fail", filePath)
+			filePath := filepath.Join(param.OutputDirPath, filepath.FromSlash(fileName.String()))
+			// 包名为输出文件所在文件夹同名，支持按表生成子包
+			setPkgName(goPkgName(filepath.Base(filepath.Dir(filePath))))
+			body := bytes.NewBuffer(nil)
+			if err := tpl.Execute(body, data); err != nil {
+				return errors.Wrapf(withTplSourceLine(err, tplFileBody), "tpl(%s) execute fail", tplSource)
 			}
-			file, err := os.Create(filePath)
+			file, err := newGeneratedFile(filePath, body.Bytes(), param)
 			if err != nil {
-				return errors.Wrapf(err, "create file(%s) fail", filePath)
+				return err
 			}
-			if err := tpl.Execute(file, tplParam); err != nil {
-				return errors.Wrapf(err, "tpl(%s) execute fail", param.TplFilePath)
+			return fileSet.add(file)
+		}
+		if param.Scope == GenerateScopeSchema {
+			schemaParam := d.getTplSchemaParam(ctx, tables, param, enums, fieldEnums)
+			err := render(schemaParam, func(pkgName string) {
+				schemaParam.PkgName = pkgName
+				for _, tplParam := range schemaParam.Tables {
+					tplParam.PkgName = pkgName
+				}
+			})
+			if err != nil {
+				return nil, err
 			}
-			if err := file.Close(); err != nil {
-				return errors.Wrapf(err, "gen file(%s) close fail", param.TplFilePath)
+			continue
+		}
+		for _, table := range tables {
+			tplParam := d.getTplParam(ctx, table, param, fieldEnums)
+			if err := render(tplParam, func(pkgName string) { tplParam.PkgName = pkgName }); err != nil {
+				return nil, err
 			}
-			// 通过go-fmt标准化文件
-			goFmtCmd := exec.CommandContext(ctx, "go", "fmt", filePath)
-			if err := goFmtCmd.Run(); err != nil {
-				return errors.Wrapf(err, "go fmt file(%s) fail", filePath)
+		}
+	}
+	return fileSet, nil
+}
+
+// newGeneratedFile 按输出文件扩展名格式化渲染结果并加上生成文件标记
+func newGeneratedFile(filePath string, content []byte, param *GenerateGoFilesParam) (*GeneratedFile, error) {
+	ext := filepath.Ext(filePath)
+	if ext == ".go" {
+		var err error
+		if content, err = formatGoSource(content, param.Imports); err != nil {
+			return nil, errors.Wrapf(err, "format file(%s) fail", filePath)
+		}
+	}
+	if !param.NoGeneratedHeader {
+		content = withGeneratedHeader(ext, content)
+	}
+	file := &GeneratedFile{Path: filePath, Content: content}
+	if ext == ".go" {
+		imports := param.Imports
+		file.reformat = func(content, old []byte) ([]byte, error) {
+			// 自定义区域中的代码可能使用已有文件中导入的路径未知的包，带入后仍被引用的import需要保留
+			oldImports := goFileImports(old)
+			for name, importPath := range imports {
+				oldImports[name] = importPath
 			}
+			return formatGoSource(content, oldImports)
 		}
 	}
-	return nil
+	return file, nil
+}
+
+// tplActionRE 模板中的{{...}}动作
+var tplActionRE = regexp.MustCompile(`{{.*?}}`)
+
+// fileNamePattern 将FileName模板转换为filepath.Match通配符，模板动作替换为*，如{{.Table.Name}}_model.go->*_model.go
+func fileNamePattern(fileName string) string {
+	var pattern strings.Builder
+	last := 0
+	for _, loc := range tplActionRE.FindAllStringIndex(fileName, -1) {
+		pattern.WriteString(escapeGlob(fileName[last:loc[0]]))
+		if !strings.HasSuffix(pattern.String(), "*") {
+			pattern.WriteString("*")
+		}
+		last = loc[1]
+	}
+	pattern.WriteString(escapeGlob(fileName[last:]))
+	return filepath.FromSlash(pattern.String())
+}
+
+// escapeGlob 转义filepath.Match中的特殊字符，Windows中filepath.Match不支持转义
+func escapeGlob(s string) string {
+	if filepath.Separator == '\\' {
+		return s
+	}
+	var ret strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[\`, r) {
+			ret.WriteByte('\\')
+		}
+		ret.WriteRune(r)
+	}
+	return ret.String()
+}
+
+// goPkgNameInvalidRE go包名中不允许出现的字符
+var goPkgNameInvalidRE = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// goPkgName 将文件夹名转换为合法的go包名，如sub-task->sub_task
+func goPkgName(dir string) string {
+	name := goPkgNameInvalidRE.ReplaceAllString(strings.ToLower(dir), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "pkg" + name
+	}
+	return name
 }
 
 // TplTable 生成文件使用到的模板表参数
 type TplTable struct {
-	VarName string // TTestName
-	CName   string // 测试表
-	Name    string // t_test
+	VarName string // 表名的大驼峰形式，如TTestName
+	CName   string // 表中文名，如测试表
+	Name    string // 表英文名，如t_test
 }
 
 // TplEnumValue 生成文件使用到的模板枚举参数
 type TplEnumValue struct {
-	VarName string // Running
-	CName   string // 运行中
-	Value   string // 1
+	VarName string // EnumValue.EName的大驼峰形式，如Running
+	Name    string // EnumValue.EName，如running
+	CName   string // EnumValue.Desc，如运行中
+	Value   string // EnumValue.Value，如1或running
+	Offline bool   // 是否已下线
+}
+
+// TplEnum 共享枚举包使用到的模板枚举参数，同一个Enum.ID只有一个
+type TplEnum struct {
+	Name       string         // 枚举类型名，取首次使用该枚举的字段名的大驼峰形式，重名时加表名前缀，如TaskStatus
+	CName      string         // 枚举中文名，如任务状态
+	Type       string         // 枚举值的类型，如int
+	IsNum      bool           // 枚举值是否为数字
+	Tables     []string       // 使用该枚举的表英文名
+	EnumValues []TplEnumValue // 所有枚举值，VarName去掉了与枚举类型名重复的前缀，常量名为Name+VarName
 }
 
 // TplField 生成文件使用到的模板字段参数
 type TplField struct {
-	VarName    string // Status
-	Type       string // int
-	Name       string // status
-	CName      string // 状态
-	IsEnum     bool   // true
-	IsNum      bool   // true
-	IsPK       bool   // true
-	AutoIncr   bool   // false
-	EnumValues []TplEnumValue
+	VarName    string         // 字段名的大驼峰形式，如Status
+	Type       string         // 字段类型，枚举字段为枚举值的类型或EnumPkg中的命名类型，指定TypeConverter时为目标语言类型，如int
+	Kind       string         // 字段的逻辑类型，与DataTypeGetter无关，见DataTypeXXX，如enum
+	Name       string         // 字段英文名，如status
+	CName      string         // 字段中文名，如状态
+	IsEnum     bool           // 是否为枚举字段
+	IsNum      bool           // 是否为数字类型，枚举字段取决于枚举值的类型
+	IsPK       bool           // 是否为主键
+	IsUnique   bool           // 是否唯一，取自TableField.IsUnique
+	AutoIncr   bool           // 是否自增
+	Nullable   bool           // 是否可为空
+	NullPtr    bool           // 可为空且Type的零值不能表示NULL，Go模型中使用指针类型，如*string
+	EnumType   string         // 枚举字段在共享枚举包中的类型名，如TaskStatus
+	EnumValues []TplEnumValue // 枚举字段的所有枚举值
 }
 
 // TplParam 生成文件使用到的模板参数
 type TplParam struct {
-	PkgName      string
-	Table        TplTable
-	Fields       []TplField
-	PKFields     []TplField
-	HasEnum      bool
-	HasDecimal   bool
-	InjectParams map[string]string
+	PkgName      string            // 包名，为OutputDirPath的最后一级目录名
+	Table        TplTable          // 表信息
+	Fields       []TplField        // 按字段ID排序的所有字段
+	PKFields     []TplField        // 主键字段，顺序与Fields一致
+	UniqueFields []TplField        // 非主键的唯一字段，顺序与Fields一致
+	InsertFields []TplField        // 插入时使用的字段，即非自增字段
+	UpdateFields []TplField        // 按主键更新时使用的字段，即非主键字段
+	AutoIncr     *TplField         // 自增字段，没有时为nil
+	ModelType    string            // 模型类型，指定ModelPkg时带包名，如model.TTask
+	HasEnum      bool              // 是否有枚举字段
+	HasDecimal   bool              // 是否有decimal.Decimal类型的字段
+	SharedEnum   bool              // 是否指定了EnumPkg，枚举定义在共享枚举包中
+	InjectParams map[string]string // GenerateGoFilesParam.InjectParams
+}
+
+// TplSchemaParam schema级模板使用到的模板参数
+type TplSchemaParam struct {
+	PkgName      string            // 包名，为输出文件所在文件夹名
+	Tables       []*TplParam       // 所有表的模板参数，顺序与传入的表一致
+	Enums        []*TplEnum        // 所有表使用到的枚举，按首次出现的顺序
+	InjectParams map[string]string // GenerateGoFilesParam.InjectParams
+}
+
+func (d *DefaultMetaCenter) getTplSchemaParam(ctx context.Context, tables []*Table,
+	genParam *GenerateGoFilesParam, enums []*genEnum, fieldEnums map[string]*genEnum) *TplSchemaParam {
+	param := &TplSchemaParam{
+		PkgName:      path.Base(genParam.OutputDirPath),
+		InjectParams: genParam.InjectParams,
+	}
+	for _, table := range tables {
+		param.Tables = append(param.Tables, d.getTplParam(ctx, table, genParam, fieldEnums))
+	}
+	for _, e := range enums {
+		tplEnum := &TplEnum{
+			Name:   e.Name,
+			CName:  e.Enum.CName,
+			Type:   d.getTplType(ctx, e.Enum.DataTypeID, genParam),
+			IsNum:  e.IsNum,
+			Tables: e.Tables,
+		}
+		for _, enumValue := range e.Enum.Values {
+			tplEnum.EnumValues = append(tplEnum.EnumValues, TplEnumValue{
+				VarName: enumValueName(e.Name, enumValue),
+				Name:    enumValue.EName,
+				CName:   enumValue.Desc,
+				Value:   enumValue.Value,
+				Offline: enumValue.IsOffline(),
+			})
+		}
+		param.Enums = append(param.Enums, tplEnum)
+	}
+	return param
 }
 
-func (d *DefaultMetaCenter) getTplParam(ctx context.Context, table *Table, genParam *GenerateGoFilesParam) *TplParam {
+// getTplParam 获取表级模板参数，fieldEnums为collectEnums返回的表名+字段名到枚举的映射，用于获取枚举类型名
+func (d *DefaultMetaCenter) getTplParam(ctx context.Context, table *Table, genParam *GenerateGoFilesParam,
+	fieldEnums map[string]*genEnum) *TplParam {
 	param := &TplParam{
 		// 包名为输出文件夹同名
 		PkgName: path.Base(genParam.OutputDirPath),
@@ -260,44 +541,106 @@ func (d *DefaultMetaCenter) getTplParam(ctx context.Context, table *Table, genPa
 			CName:   table.CName,
 			Name:    table.Name,
 		},
+		SharedEnum:   genParam.EnumPkg != "",
+		ModelType:    strcase.ToCamel(table.Name),
 		InjectParams: genParam.InjectParams,
 	}
+	if genParam.ModelPkg != "" && genParam.ModelPkg != "." {
+		param.ModelType = path.Base(genParam.ModelPkg) + "." + param.ModelType
+	}
 	for _, field := range table.Fields {
 		fieldVarName := strcase.ToCamel(field.Name)
 		dataType := d.dataTypeGetter.GetByID(ctx, field.Type)
 		tplField := TplField{
 			VarName:  fieldVarName,
-			Type:     dataType.Name,
+			Type:     d.getTplType(ctx, field.Type, genParam),
+			Kind:     d.getFieldKind(ctx, field),
 			Name:     field.Name,
 			CName:    field.CName,
 			IsNum:    dataType.IsNum,
 			IsPK:     field.IsPK,
 			AutoIncr: field.AutoIncr,
+			Nullable: field.Nullable,
 			IsEnum:   false,
 		}
+		if tableField := table.GetTableField(field.ID); tableField != nil {
+			tplField.IsPK = tplField.IsPK || tableField.IsPrimaryKey == 1
+			tplField.IsUnique = tableField.IsUnique == 1
+		}
 		if field.Enum != nil {
 			param.HasEnum = true
 			tplField.IsEnum = true
-			tplField.Type = d.dataTypeGetter.GetByID(ctx, field.Enum.DataTypeID).Name
+			tplField.Type = d.getTplType(ctx, field.Enum.DataTypeID, genParam)
+			// 枚举字段是否为数字取决于枚举值的类型
+			tplField.IsNum = d.isNumEnum(ctx, field.Enum)
+			if e, ok := fieldEnums[table.Name+"."+field.Name]; ok {
+				tplField.EnumType = e.Name
+				if genParam.EnumPkg == "." {
+					tplField.Type = e.Name
+				} else if genParam.EnumPkg != "" {
+					tplField.Type = path.Base(genParam.EnumPkg) + "." + e.Name
+				}
+			}
 			for _, enumValue := range field.Enum.Values {
 				tplField.EnumValues = append(tplField.EnumValues, TplEnumValue{
 					VarName: strcase.ToCamel(enumValue.EName),
+					Name:    enumValue.EName,
 					CName:   enumValue.Desc,
 					Value:   enumValue.Value,
+					Offline: enumValue.IsOffline(),
 				})
 			}
 		}
 		if dataType.Name == goDecimalType {
 			param.HasDecimal = true
 		}
+		tplField.NullPtr = tplField.Nullable && !tplField.IsPK && !tplField.AutoIncr &&
+			!goNullSafeType(tplField.Type, tplField.EnumType != "" && genParam.EnumPkg != "")
 		param.Fields = append(param.Fields, tplField)
-		if field.IsPK {
+		if tplField.IsPK {
 			param.PKFields = append(param.PKFields, tplField)
+		} else {
+			param.UpdateFields = append(param.UpdateFields, tplField)
+			if tplField.IsUnique {
+				param.UniqueFields = append(param.UniqueFields, tplField)
+			}
+		}
+		if tplField.AutoIncr {
+			autoIncr := tplField
+			param.AutoIncr = &autoIncr
+		} else {
+			param.InsertFields = append(param.InsertFields, tplField)
 		}
 	}
 	return param
 }
 
+// goNullSafeType Go类型能否直接读写NULL，共享枚举以及utils.DateTime的零值即为NULL，
+// 指针、切片、map、接口以及sql.NullXXX本身可表示NULL
+func goNullSafeType(typ string, sharedEnum bool) bool {
+	if sharedEnum || typ == "utils.DateTime" || typ == "interface{}" || typ == "any" {
+		return true
+	}
+	for _, prefix := range []string{"*", "[]", "map[", "sql.Null"} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTplType 获取模板中使用的字段类型，指定了TypeConverter时按逻辑类型转换为目标语言类型
+func (d *DefaultMetaCenter) getTplType(ctx context.Context, typeID int, genParam *GenerateGoFilesParam) string {
+	if genParam.TypeConverter == nil {
+		return d.dataTypeGetter.GetByID(ctx, typeID).Name
+	}
+	dataType := genParam.TypeConverter.GetByName(ctx, d.getTypeKind(ctx, typeID))
+	if dataType == nil {
+		return d.dataTypeGetter.GetByID(ctx, typeID).Name
+	}
+	return dataType.Name
+}
+
 var fmtMySQLDDLRE = regexp.MustCompile(`shardkey=.*`)
 
 // ParseFromMySQLDDL 将MySQL-DDL语句转化为定义的meta结构
@@ -328,13 +671,20 @@ func (d *DefaultMetaCenter) ParseFromMySQLDDL(ctx context.Context, ddl string) (
 	ret := d.parseMySQLDDLTable(stmt)
 	for _, col := range stmt.Cols {
 		field := d.parseMySQLDDLField(ctx, col)
-		// 补充pk以及autoincr信息
+		// 补充pk、autoincr以及是否可为空信息，mysql中非主键字段未声明NOT NULL时可为空
 		if pkFields[field.Name] {
 			field.IsPK = true
 		}
+		field.Nullable = !field.IsPK
 		for _, colOpt := range col.Options {
-			if colOpt.Tp == ast.ColumnOptionAutoIncrement {
+			switch colOpt.Tp {
+			case ast.ColumnOptionAutoIncrement:
 				field.AutoIncr = true
+			case ast.ColumnOptionPrimaryKey:
+				field.IsPK = true
+				field.Nullable = false
+			case ast.ColumnOptionNotNull:
+				field.Nullable = false
 			}
 		}
 		ret.Fields = append(ret.Fields, field)
@@ -342,7 +692,7 @@ func (d *DefaultMetaCenter) ParseFromMySQLDDL(ctx context.Context, ddl string) (
 	return ret, nil
 }
 
-var mysqlTypeRE = regexp.MustCompile(`^(\w+)\(\d+\)$`)
+var mysqlTypeRE = regexp.MustCompile(`^(\w+)\(\d+(,\d+)?\)$`)
 
 func (d *DefaultMetaCenter) parseMySQLDDLField(ctx context.Context, col *ast.ColumnDef) *Field {
 	// 解析字段英文名
@@ -356,6 +706,16 @@ func (d *DefaultMetaCenter) parseMySQLDDLField(ctx context.Context, col *ast.Col
 		tp = matches[1]
 	}
 	field.Type = d.dataTypeGetter.GetByName(ctx, tp).ID
+	// 解析小数的精度，未指定时mysql默认为decimal(10,0)
+	if tp == "decimal" {
+		field.Precision, field.Scale = col.Tp.Flen, col.Tp.Decimal
+		if field.Precision <= 0 {
+			field.Precision = 10
+		}
+		if field.Scale < 0 {
+			field.Scale = 0
+		}
+	}
 	// 解析字段注释，尝试解析字段的中文名
 	// 以及如果有枚举值解析为枚举类型，否则如果是字符串类型且包含JSON字样解析为JSON
 	for _, option := range col.Options {
@@ -460,28 +820,41 @@ func (*DefaultMetaCenter) parseMySQLDDLTable(stmt *ast.CreateTableStmt) *Table {
 	return ret
 }
 
-// ESTemplate es模板配置
+// ESTemplateSettings es模板的索引设置
+type ESTemplateSettings struct {
+	MaxResultWindow  int `json:"max_result_window"`
+	NumberOfShards   int `json:"number_of_shards"`
+	NumberOfReplicas int `json:"number_of_replicas"`
+}
+
+// ESTemplateMappings es模板的映射配置
+type ESTemplateMappings struct {
+	Source struct {
+		Enabled bool `json:"enabled"`
+	} `json:"_source"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// ESTemplate es模板配置，对应ES7起的composable模板
 type ESTemplate struct {
 	IndexPatterns []string `json:"index_patterns"`
 	Template      struct {
-		Settings struct {
-			MaxResultWindow  int `json:"max_result_window"`
-			NumberOfShards   int `json:"number_of_shards"`
-			NumberOfReplicas int `json:"number_of_replicas"`
-		} `json:"settings"`
-		Mappings struct {
-			Source struct {
-				Enabled bool `json:"enabled"`
-			} `json:"_source"`
-			Properties map[string]interface{} `json:"properties"`
-		} `json:"mappings"`
+		Settings ESTemplateSettings `json:"settings"`
+		Mappings ESTemplateMappings `json:"mappings"`
 	} `json:"template"`
 	Priority int `json:"priority"`
 	Version  int `json:"version"`
 }
 
-// ToESTemplate 将Table转换为es模板
-func (d *DefaultMetaCenter) ToESTemplate(ctx context.Context, table *Table) (string, error) {
+// ToESTemplate 将Table转换为es模板，默认生成ES7的composable模板，可通过WithESTarget指定目标集群
+func (d *DefaultMetaCenter) ToESTemplate(ctx context.Context, table *Table, opts ...ESTemplateOption) (string, error) {
+	options := &esTemplateOptions{target: ESTargetES7}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if !isValidESTarget(options.target) {
+		return "", fmt.Errorf("unknown es target(%s)", options.target)
+	}
 	tpl := ESTemplate{}
 	indexConfig := table.ESConfig.Index
 	tpl.IndexPatterns = []string{indexConfig.NameOrPrefix}
@@ -499,44 +872,61 @@ func (d *DefaultMetaCenter) ToESTemplate(ctx context.Context, table *Table) (str
 		tpl.Template.Settings.NumberOfReplicas = indexConfig.NumberOfReplicas
 	}
 	tpl.Template.Mappings.Source.Enabled = true
+	tpl.Template.Mappings.Properties = make(map[string]interface{}, len(table.Fields))
 	for _, field := range table.Fields {
-		var fieldMapping map[string]interface{}
-		typeName := d.dataTypeGetter.GetByID(ctx, field.Type).Name
-		switch typeName {
-		case DataTypeInt:
-			fieldMapping = map[string]interface{}{"type": "long"}
-		case DataTypeUInt:
-			fieldMapping = map[string]interface{}{"type": "unsigned_long"}
-		case DataTypeFloat:
-			fieldMapping = map[string]interface{}{"type": "double"}
-		case DataTypeDateTime:
-			fieldMapping = map[string]interface{}{
-				"type": "date", "format": "yyyy-MM-dd HH:mm:ss", "ignore_malformed": true}
-		case DataTypeEnum:
-			fieldMapping = map[string]interface{}{"type": "keyword"}
-			enumTypeName := d.dataTypeGetter.GetByID(ctx, field.Enum.DataTypeID).Name
-			if enumTypeName == DataTypeInt || enumTypeName == DataTypeUInt {
-				fieldMapping = map[string]interface{}{"type": "long"}
-			}
-		case DataTypeJSON:
-			fieldMapping = map[string]interface{}{"type": "nested"}
-		default:
-			fieldMapping = map[string]interface{}{"type": "keyword"}
-			if field.ESFieldType == "text" {
-				fieldMapping = map[string]interface{}{
-					"type":            "text",
-					"search_analyzer": "ik_smart",
-					"analyzer":        "ik_max_word",
-					"fields": map[string]interface{}{
-						"keyword": map[string]interface{}{
-							"type": "keyword",
-						},
-					},
-				}
-			}
-		}
-		tpl.Template.Mappings.Properties[field.Name] = fieldMapping
+		tpl.Template.Mappings.Properties[field.Name] = adjustESFieldMapping(d.esFieldMapping(ctx, field), options.target)
+	}
+	if options.target == ESTargetES6 {
+		body, _ := json.Marshal(toESLegacyTemplate(&tpl))
+		return string(body), nil
 	}
 	body, _ := json.Marshal(tpl)
 	return string(body), nil
 }
+
+// esFieldMapping 获取字段在es模板中的映射配置
+func (d *DefaultMetaCenter) esFieldMapping(ctx context.Context, field *Field) map[string]interface{} {
+	// 有枚举值的字段按枚举值的类型映射，未指定枚举值类型时按字段类型
+	if d.getFieldKind(ctx, field) == fieldKindEnum && field.Enum.DataTypeID != 0 {
+		if d.isNumEnum(ctx, field.Enum) {
+			return map[string]interface{}{"type": "long"}
+		}
+		return map[string]interface{}{"type": "keyword"}
+	}
+	typeName := d.dataTypeGetter.GetByID(ctx, field.Type).Name
+	switch typeName {
+	case DataTypeInt:
+		return map[string]interface{}{"type": "long"}
+	case DataTypeUInt:
+		return map[string]interface{}{"type": "unsigned_long"}
+	case DataTypeFloat:
+		return map[string]interface{}{"type": "double"}
+	case DataTypeDateTime:
+		return map[string]interface{}{
+			"type": "date", "format": "yyyy-MM-dd HH:mm:ss", "ignore_malformed": true}
+	case DataTypeEnum:
+		if field.Enum == nil {
+			return map[string]interface{}{"type": "keyword"}
+		}
+		enumTypeName := d.dataTypeGetter.GetByID(ctx, field.Enum.DataTypeID).Name
+		if enumTypeName == DataTypeInt || enumTypeName == DataTypeUInt {
+			return map[string]interface{}{"type": "long"}
+		}
+		return map[string]interface{}{"type": "keyword"}
+	case DataTypeJSON:
+		return map[string]interface{}{"type": "nested"}
+	}
+	if field.ESFieldType == "text" {
+		return map[string]interface{}{
+			"type":            "text",
+			"search_analyzer": "ik_smart",
+			"analyzer":        "ik_max_word",
+			"fields": map[string]interface{}{
+				"keyword": map[string]interface{}{
+					"type": "keyword",
+				},
+			},
+		}
+	}
+	return map[string]interface{}{"type": "keyword"}
+}
@@ -164,7 +164,14 @@ func TestDefaultMetaCenter_GenerateGoFiles(t *testing.T) {
 				return &DataType{Name: DataTypeInt}
 			})
 			defer p1.Reset()
-			if err := d.GenerateGoFiles(tt.args.ctx); (err != nil) != tt.wantErr {
+			outputDirPath := t.TempDir() + "/model"
+			params := []*GenerateGoFilesParam{
+				{Name: "model", TplFilePath: "./tpl_files/model.tpl", OutputDirPath: outputDirPath},
+				{Name: "const", OutputDirPath: outputDirPath},
+				{Name: "model", TplFilePath: "./tpl_files/ts.tpl", OutputDirPath: outputDirPath, Ext: ".ts",
+					Scope: GenerateScopeSchema, TypeConverter: NewTypeScriptDataTypeGetter()},
+			}
+			if err := d.GenerateGoFiles(tt.args.ctx, d.GetAllTables(tt.args.ctx), params); (err != nil) != tt.wantErr {
 				t.Errorf("DefaultMetaCenter.GenerateGoFiles() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -221,14 +228,17 @@ func TestDefaultMetaCenter_ParseFromMySQLDDL(t *testing.T) {
 				CName: "tabletestcomment",
 				Fields: []*Field{
 					{
-						Name:  "id",
-						CName: "pk-id",
-						Type:  1,
+						Name:     "id",
+						CName:    "pk-id",
+						Type:     1,
+						IsPK:     true,
+						AutoIncr: true,
 					},
 					{
-						Name:  "s",
-						CName: "testcomment",
-						Type:  2,
+						Name:     "s",
+						CName:    "testcomment",
+						Type:     2,
+						Nullable: true,
 					},
 				},
 			},
@@ -246,7 +256,7 @@ func TestDefaultMetaCenter_ParseFromMySQLDDL(t *testing.T) {
 				dataTypeGetter:   tt.fields.dataTypeGetter,
 			}
 			p0 := gomonkey.ApplyMethodFunc(tt.fields.dataTypeGetter, "GetByName", func(ctx context.Context, name string) *DataType {
-				if name == "string" {
+				if name == "string" || name == "char" {
 					return &DataType{ID: 2}
 				}
 				if name == "int" {
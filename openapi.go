@@ -0,0 +1,181 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
+)
+
+// OpenAPIVersion 生成的OpenAPI版本，3.1的schema与JSON Schema 2020-12兼容
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIParam 生成OpenAPI文档可指定的参数
+type OpenAPIParam struct {
+	// Title 文档标题
+	Title string
+	// Version 接口版本
+	Version string
+	// Description 文档描述
+	Description string
+	// Servers 服务地址列表
+	Servers []string
+	// WithCRUD 是否按主键生成增删改查接口
+	WithCRUD bool
+	// BasePath 增删改查接口的路径前缀，如/api/v1
+	BasePath string
+}
+
+// ToOpenAPI 将表转换为OpenAPI 3文档，components.schemas中包含各表以及共享的枚举
+// 枚举schema使用x-enum-descriptions给出各枚举值的描述，与enum一一对应，可为空的枚举中null对应的描述为null
+// 字段类型与getTplParam一样按逻辑类型(TplField.Kind)映射，但不使用TplField.Type：其为DataTypeGetter中的
+// 语言类型名(如utils.DateTime)，不是OpenAPI类型，因此复用JSON Schema的映射；主键同样按getTplParam的规则判断
+func (d *DefaultMetaCenter) ToOpenAPI(ctx context.Context, tables []*Table, param *OpenAPIParam) (string, error) {
+	if param == nil {
+		param = &OpenAPIParam{}
+	}
+	b := d.newJSONSchemaBuilder(ctx, tables)
+	b.refPrefix = "#/components/schemas/"
+	b.enumDescKey = "x-enum-descriptions"
+	schemas := b.defs()
+	for _, table := range tables {
+		name := strcase.ToCamel(table.Name)
+		if _, ok := schemas[name]; ok {
+			return "", fmt.Errorf("schema name(%s) of table(%s) conflicts with enum", name, table.Name)
+		}
+		schemas[name] = b.tableSchema(table)
+	}
+	doc := map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":       param.Title,
+			"version":     param.Version,
+			"description": param.Description,
+		},
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+	if len(param.Servers) != 0 {
+		servers := make([]interface{}, 0, len(param.Servers))
+		for _, server := range param.Servers {
+			servers = append(servers, map[string]interface{}{"url": server})
+		}
+		doc["servers"] = servers
+	}
+	paths := make(map[string]interface{})
+	if param.WithCRUD {
+		for _, table := range tables {
+			b.addCRUDPaths(paths, strings.TrimRight(param.BasePath, "/"), table)
+		}
+	}
+	doc["paths"] = paths
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal openapi doc fail")
+	}
+	return string(body), nil
+}
+
+// addCRUDPaths 生成表的增删改查接口：
+// GET/POST {base}/{table} 分页列表以及新增，GET/PUT/DELETE {base}/{table}/{pk...} 按主键查询、更新以及删除
+func (b *jsonSchemaBuilder) addCRUDPaths(paths map[string]interface{}, basePath string, table *Table) {
+	name := strcase.ToCamel(table.Name)
+	ref := map[string]interface{}{"$ref": b.refPrefix + name}
+	jsonContent := func(schema interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		}
+	}
+	tag := table.CName
+	if tag == "" {
+		tag = table.Name
+	}
+	collectionPath := basePath + "/" + table.Name
+	paths[collectionPath] = map[string]interface{}{
+		"get": map[string]interface{}{
+			"operationId": "list" + name,
+			"summary":     "分页获取" + tag,
+			"tags":        []string{tag},
+			"parameters": []interface{}{
+				map[string]interface{}{"name": "page", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1}},
+				map[string]interface{}{"name": "size", "in": "query", "schema": map[string]interface{}{"type": "integer", "minimum": 1}},
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "OK",
+					"content": jsonContent(map[string]interface{}{
+						"type":  "array",
+						"items": ref,
+					}),
+				},
+			},
+		},
+		"post": map[string]interface{}{
+			"operationId": "create" + name,
+			"summary":     "新增" + tag,
+			"tags":        []string{tag},
+			"requestBody": map[string]interface{}{"required": true, "content": jsonContent(ref)},
+			"responses": map[string]interface{}{
+				"201": map[string]interface{}{"description": "Created", "content": jsonContent(ref)},
+			},
+		},
+	}
+	var pkFields []*Field
+	for _, field := range table.Fields {
+		if isTablePK(table, field) {
+			pkFields = append(pkFields, field)
+		}
+	}
+	if len(pkFields) == 0 {
+		return
+	}
+	itemPath := collectionPath
+	parameters := make([]interface{}, 0, len(pkFields))
+	for _, field := range pkFields {
+		itemPath += "/{" + field.Name + "}"
+		schema := b.fieldSchema(table, field)
+		delete(schema, "title")
+		delete(schema, "description")
+		parameters = append(parameters, map[string]interface{}{
+			"name":        field.Name,
+			"in":          "path",
+			"required":    true,
+			"description": field.CName,
+			"schema":      schema,
+		})
+	}
+	notFound := map[string]interface{}{"description": "Not Found"}
+	paths[itemPath] = map[string]interface{}{
+		"parameters": parameters,
+		"get": map[string]interface{}{
+			"operationId": "get" + name,
+			"summary":     "按主键获取" + tag,
+			"tags":        []string{tag},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "OK", "content": jsonContent(ref)},
+				"404": notFound,
+			},
+		},
+		"put": map[string]interface{}{
+			"operationId": "update" + name,
+			"summary":     "按主键更新" + tag,
+			"tags":        []string{tag},
+			"requestBody": map[string]interface{}{"required": true, "content": jsonContent(ref)},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "OK", "content": jsonContent(ref)},
+				"404": notFound,
+			},
+		},
+		"delete": map[string]interface{}{
+			"operationId": "delete" + name,
+			"summary":     "按主键删除" + tag,
+			"tags":        []string{tag},
+			"responses": map[string]interface{}{
+				"204": map[string]interface{}{"description": "No Content"},
+				"404": notFound,
+			},
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToOpenAPI(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	got, err := d.ToOpenAPI(ctx, newGenTestTables(), &OpenAPIParam{
+		Title:    "任务",
+		Version:  "1.0.0",
+		Servers:  []string{"https://example.com"},
+		WithCRUD: true,
+		BasePath: "/api/v1/",
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToOpenAPI() error = %v", err)
+	}
+	var doc struct {
+		OpenAPI    string                            `json:"openapi"`
+		Info       map[string]interface{}            `json:"info"`
+		Servers    []map[string]interface{}          `json:"servers"`
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("DefaultMetaCenter.ToOpenAPI() invalid json = %v", err)
+	}
+	if doc.OpenAPI != OpenAPIVersion || doc.Info["title"] != "任务" || doc.Servers[0]["url"] != "https://example.com" {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() header = %v %v %v", doc.OpenAPI, doc.Info, doc.Servers)
+	}
+
+	schemas := doc.Components.Schemas
+	var names []string
+	for name := range schemas {
+		names = append(names, name)
+	}
+	if len(schemas) != 3 || schemas["TTask"] == nil || schemas["TSubTask"] == nil || schemas["TaskStatus"] == nil {
+		t.Fatalf("DefaultMetaCenter.ToOpenAPI() schemas = %v, want TTask/TSubTask/TaskStatus", names)
+	}
+	// 被多个表使用的枚举放到components中共享，已下线的枚举值不出现
+	wantStatus := map[string]interface{}{
+		"type":                "integer",
+		"title":               "任务状态",
+		"enum":                []interface{}{float64(1), float64(2)},
+		"x-enum-descriptions": []interface{}{"待执行", "已完成"},
+	}
+	if !reflect.DeepEqual(schemas["TaskStatus"], wantStatus) {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() TaskStatus = %v, want %v", schemas["TaskStatus"], wantStatus)
+	}
+	properties := schemas["TTask"]["properties"].(map[string]interface{})
+	wantRef := map[string]interface{}{"$ref": "#/components/schemas/TaskStatus", "title": "任务状态"}
+	if !reflect.DeepEqual(properties["task_status"], wantRef) {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() task_status = %v, want %v", properties["task_status"], wantRef)
+	}
+	wantPhase := map[string]interface{}{
+		"type":                []interface{}{"string", "null"},
+		"title":               "任务阶段",
+		"enum":                []interface{}{"parse_file", "send_file", nil},
+		"x-enum-descriptions": []interface{}{"解析文件", "发送文件", "null"},
+	}
+	if !reflect.DeepEqual(properties["phase"], wantPhase) {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() phase = %v, want %v", properties["phase"], wantPhase)
+	}
+
+	wantOperations := map[string][]string{
+		"/api/v1/t_task":          {"get", "post"},
+		"/api/v1/t_task/{id}":     {"get", "put", "delete"},
+		"/api/v1/t_sub_task":      {"get", "post"},
+		"/api/v1/t_sub_task/{id}": {"get", "put", "delete"},
+	}
+	if len(doc.Paths) != len(wantOperations) {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() paths = %v", doc.Paths)
+	}
+	for path, operations := range wantOperations {
+		for _, operation := range operations {
+			if doc.Paths[path][operation] == nil {
+				t.Errorf("DefaultMetaCenter.ToOpenAPI() path(%s) should have operation(%s)", path, operation)
+			}
+		}
+	}
+	param := doc.Paths["/api/v1/t_task/{id}"]["parameters"].([]interface{})[0].(map[string]interface{})
+	wantParam := map[string]interface{}{
+		"name": "id", "in": "path", "required": true, "description": "自增ID",
+		"schema": map[string]interface{}{"type": "integer", "minimum": float64(0)},
+	}
+	if !reflect.DeepEqual(param, wantParam) {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() id param = %v, want %v", param, wantParam)
+	}
+
+	// 表名与共享枚举名冲突
+	tables := append(newGenTestTables(), &Table{ID: 3, Name: "task_status", Fields: []*Field{{ID: 1, Name: "id", Type: 1}}})
+	if _, err := d.ToOpenAPI(ctx, tables, nil); err == nil {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() with conflict name should return error")
+	}
+
+	// 通过TableField标记的主键同样生成按主键操作的接口
+	item := &Table{
+		ID:          1,
+		Name:        "t_item",
+		Fields:      []*Field{{ID: 1, Name: "item_id", Type: 1}, {ID: 2, Name: "name", Type: 3}},
+		TableFields: map[int]*TableField{1: {TableID: 1, FieldID: 1, IsPrimaryKey: 1}},
+	}
+	got, err = d.ToOpenAPI(ctx, []*Table{item}, &OpenAPIParam{WithCRUD: true})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToOpenAPI() error = %v", err)
+	}
+	doc.Paths = nil
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("DefaultMetaCenter.ToOpenAPI() invalid json = %v", err)
+	}
+	if doc.Paths["/t_item/{item_id}"]["get"] == nil {
+		t.Errorf("DefaultMetaCenter.ToOpenAPI() paths = %v, want /t_item/{item_id}", doc.Paths)
+	}
+}
@@ -0,0 +1,195 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// protoMaxFieldNumber proto字段编号最大值
+const protoMaxFieldNumber = 1<<29 - 1
+
+// ProtoParam 生成proto文件可指定的参数
+type ProtoParam struct {
+	// Package proto包名，如metacenter.task
+	Package string
+	// Options 文件级option，如go_package->github.com/xxx/pb
+	Options map[string]string
+	// JSONAsString JSON字段使用string而不是google.protobuf.Struct
+	JSONAsString bool
+}
+
+// ToProto 将表以及表使用到的枚举转换为proto3定义
+// 消息字段编号使用Field.ID，保证字段增删后编号稳定；枚举按Enum.ID只生成一次
+// 消息名与枚举名同在一个proto命名空间，驼峰表名与其他表或枚举冲突时返回错误
+func (d *DefaultMetaCenter) ToProto(ctx context.Context, tables []*Table, param *ProtoParam) (string, error) {
+	if param == nil {
+		param = &ProtoParam{}
+	}
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	names := make(map[string]bool, len(enums)+len(tables))
+	for _, e := range enums {
+		names[e.Name] = true
+	}
+	for _, table := range tables {
+		name := strcase.ToCamel(table.Name)
+		if names[name] {
+			return "", fmt.Errorf("message name(%s) of table(%s) conflicts with another table or enum", name, table.Name)
+		}
+		names[name] = true
+	}
+	imports := make(map[string]bool)
+	messages := bytes.NewBuffer(nil)
+	for _, table := range tables {
+		if err := d.writeProtoMessage(ctx, messages, table, fieldEnums, param, imports); err != nil {
+			return "", err
+		}
+	}
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	if param.Package != "" {
+		fmt.Fprintf(buf, "package %s;\n\n", param.Package)
+	}
+	if len(imports) != 0 {
+		importPaths := make([]string, 0, len(imports))
+		for importPath := range imports {
+			importPaths = append(importPaths, importPath)
+		}
+		sort.Strings(importPaths)
+		for _, importPath := range importPaths {
+			fmt.Fprintf(buf, "import \"%s\";\n", importPath)
+		}
+		buf.WriteString("\n")
+	}
+	if len(param.Options) != 0 {
+		optionNames := make([]string, 0, len(param.Options))
+		for name := range param.Options {
+			optionNames = append(optionNames, name)
+		}
+		sort.Strings(optionNames)
+		for _, name := range optionNames {
+			fmt.Fprintf(buf, "option %s = %s;\n", name, strconv.Quote(param.Options[name]))
+		}
+		buf.WriteString("\n")
+	}
+	for _, e := range enums {
+		if err := writeProtoEnum(buf, e); err != nil {
+			return "", err
+		}
+	}
+	buf.Write(messages.Bytes())
+	return strings.TrimRight(buf.String(), "\n") + "\n", nil
+}
+
+func (d *DefaultMetaCenter) writeProtoMessage(ctx context.Context, buf *bytes.Buffer, table *Table,
+	fieldEnums map[string]*genEnum, param *ProtoParam, imports map[string]bool) error {
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "// %s %s\n", strcase.ToCamel(table.Name), comment)
+	}
+	fmt.Fprintf(buf, "message %s {\n", strcase.ToCamel(table.Name))
+	numbers := make(map[int]string)
+	for _, field := range table.Fields {
+		if field.ID <= 0 || field.ID > protoMaxFieldNumber || (field.ID >= 19000 && field.ID <= 19999) {
+			return fmt.Errorf("field(%s) of table(%s) has invalid proto field number(%d)", field.Name, table.Name, field.ID)
+		}
+		if name, ok := numbers[field.ID]; ok {
+			return fmt.Errorf("field(%s) and field(%s) of table(%s) have the same id(%d)", name, field.Name, table.Name, field.ID)
+		}
+		numbers[field.ID] = field.Name
+		var protoType string
+		switch d.getFieldKind(ctx, field) {
+		case fieldKindInt:
+			protoType = "int64"
+		case fieldKindUInt:
+			protoType = "uint64"
+		case fieldKindFloat:
+			protoType = "double"
+		case fieldKindDateTime:
+			protoType = "google.protobuf.Timestamp"
+			imports["google/protobuf/timestamp.proto"] = true
+		case fieldKindJSON:
+			protoType = "string"
+			if !param.JSONAsString {
+				protoType = "google.protobuf.Struct"
+				imports["google/protobuf/struct.proto"] = true
+			}
+		case fieldKindEnum:
+			protoType = fieldEnums[table.Name+"."+field.Name].Name
+		default:
+			// decimal使用字符串避免精度丢失
+			protoType = "string"
+		}
+		if comment := genComment(field.CName, field.Explain); comment != "" {
+			fmt.Fprintf(buf, "  // %s\n", comment)
+		}
+		fmt.Fprintf(buf, "  %s %s = %d;\n", protoType, strcase.ToSnake(field.Name), field.ID)
+	}
+	buf.WriteString("}\n\n")
+	return nil
+}
+
+// writeProtoEnum 生成proto枚举，值名称为枚举名前缀+EName的大写下划线形式
+// 数字枚举使用枚举值作为编号，字符串枚举使用EnumValue.ID(为0时使用序号)作为编号
+// proto3要求第一个值为0，枚举值中没有0时补充XXX_UNSPECIFIED = 0
+func writeProtoEnum(buf *bytes.Buffer, e *genEnum) error {
+	prefix := strcase.ToScreamingSnake(e.Name)
+	type protoEnumValue struct {
+		name    string
+		number  int64
+		comment string
+	}
+	values := make([]protoEnumValue, 0, len(e.Enum.Values)+1)
+	hasZero := false
+	numbers := make(map[int64]bool)
+	for i, enumValue := range e.Enum.Values {
+		number := int64(enumValue.ID)
+		if e.IsNum {
+			var err error
+			if number, err = strconv.ParseInt(enumValue.Value, 10, 32); err != nil {
+				return fmt.Errorf("enum(%s) value(%s) is not a valid proto enum number", e.Name, enumValue.Value)
+			}
+		} else if number <= 0 {
+			number = int64(i + 1)
+		}
+		if numbers[number] {
+			return fmt.Errorf("enum(%s) has duplicate number(%d)", e.Name, number)
+		}
+		numbers[number] = true
+		comment := genComment(enumValue.Desc, enumValue.Explain)
+		if !e.IsNum {
+			comment = strings.TrimSpace(enumValue.Value + " " + comment)
+		}
+		values = append(values, protoEnumValue{
+			name:    prefix + "_" + strcase.ToScreamingSnake(enumValueName(e.Name, enumValue)),
+			number:  number,
+			comment: comment,
+		})
+		if number == 0 {
+			hasZero = true
+		}
+	}
+	if !hasZero {
+		values = append(values, protoEnumValue{name: prefix + "_UNSPECIFIED", number: 0})
+	}
+	// 0值需要排在第一个
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].number == 0 && values[j].number != 0
+	})
+	if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+		fmt.Fprintf(buf, "// %s %s\n", e.Name, comment)
+	}
+	fmt.Fprintf(buf, "enum %s {\n", e.Name)
+	for _, value := range values {
+		if value.comment != "" {
+			fmt.Fprintf(buf, "  // %s\n", value.comment)
+		}
+		fmt.Fprintf(buf, "  %s = %d;\n", value.name, value.number)
+	}
+	buf.WriteString("}\n\n")
+	return nil
+}
@@ -0,0 +1,97 @@
+package metacenter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToProto(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	want := `syntax = "proto3";
+
+package metacenter.task;
+
+import "google/protobuf/struct.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/imqishi/pb";
+
+// TaskStatus 任务状态
+enum TaskStatus {
+  TASK_STATUS_UNSPECIFIED = 0;
+  // 待执行
+  TASK_STATUS_WAIT = 1;
+  // 已完成
+  TASK_STATUS_FINISH = 2;
+  // 已失败
+  TASK_STATUS_FAIL = 3;
+}
+
+// Phase 任务阶段
+enum Phase {
+  PHASE_UNSPECIFIED = 0;
+  // parse_file 解析文件
+  PHASE_PARSE_FILE = 4;
+  // send_file 发送文件
+  PHASE_SEND_FILE = 5;
+}
+
+// TTask 任务表
+message TTask {
+  // 自增ID
+  uint64 id = 1;
+  // 任务状态
+  TaskStatus task_status = 2;
+  // 任务阶段
+  Phase phase = 3;
+  // 扩展信息 json对象
+  google.protobuf.Struct ext = 4;
+  // 创建时间
+  google.protobuf.Timestamp create_time = 5;
+}
+
+// TSubTask 子任务表
+message TSubTask {
+  // 自增ID
+  uint64 id = 1;
+  // 任务ID
+  uint64 task_id = 6;
+  // 任务状态
+  TaskStatus task_status = 2;
+  // 得分
+  double score = 7;
+}
+`
+	got, err := d.ToProto(ctx, newGenTestTables(), &ProtoParam{
+		Package: "metacenter.task",
+		Options: map[string]string{"go_package": "github.com/imqishi/pb"},
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToProto() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("DefaultMetaCenter.ToProto() = %s, want %s", got, want)
+	}
+
+	tables := newGenTestTables()
+	tables[0].Fields[1].ID = 1
+	if _, err := d.ToProto(ctx, tables, nil); err == nil {
+		t.Errorf("DefaultMetaCenter.ToProto() duplicate field id should fail")
+	}
+
+	tables = append(newGenTestTables(), &Table{
+		Name:   "task_status",
+		Fields: []*Field{{ID: 1, Name: "id", Type: 2, IsPK: true}},
+	})
+	if _, err := d.ToProto(ctx, tables, nil); err == nil {
+		t.Errorf("DefaultMetaCenter.ToProto() message name conflicts with enum should fail")
+	}
+	tables = append(newGenTestTables(), &Table{
+		Name:   "T_task",
+		Fields: []*Field{{ID: 1, Name: "id", Type: 2, IsPK: true}},
+	})
+	if _, err := d.ToProto(ctx, tables, nil); err == nil {
+		t.Errorf("DefaultMetaCenter.ToProto() duplicate message name should fail")
+	}
+}
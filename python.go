@@ -0,0 +1,301 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	// PythonStylePydantic 生成pydantic模型
+	PythonStylePydantic = "pydantic"
+	// PythonStyleDataclass 生成标准库dataclass
+	PythonStyleDataclass = "dataclass"
+)
+
+// PythonParam 生成Python文件可指定的参数
+type PythonParam struct {
+	// Style 生成风格，见PythonStyleXXX，默认为pydantic
+	Style string
+	// EnumAsLiteral 枚举字段使用Literal类型而不是Enum类
+	EnumAsLiteral bool
+}
+
+// pythonKeywords Python关键字，字段名与之冲突时加下划线后缀
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonField 生成Python使用的字段信息
+type pythonField struct {
+	field *Field
+	// name 属性名，与Python关键字冲突时为field.Name+_
+	name string
+	// pyType 属性类型，不包含Optional
+	pyType string
+	// optional 是否可为None，可为空以及自增的字段可为None
+	optional bool
+}
+
+// ToPython 将表转换为Python模型定义，表使用到的枚举转换为IntEnum/str Enum以及对应的Literal类型
+// 字段描述取CName，可为空以及自增字段为Optional并默认为None
+func (d *DefaultMetaCenter) ToPython(ctx context.Context, tables []*Table, param *PythonParam) (string, error) {
+	if param == nil {
+		param = &PythonParam{}
+	}
+	style := param.Style
+	if style == "" {
+		style = PythonStylePydantic
+	}
+	if style != PythonStylePydantic && style != PythonStyleDataclass {
+		return "", fmt.Errorf("unknown python style(%s)", style)
+	}
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	typingImports := map[string]bool{}
+	stdImports := map[string]bool{}
+	body := bytes.NewBuffer(nil)
+	for _, e := range enums {
+		if err := writePythonEnum(body, e); err != nil {
+			return "", err
+		}
+	}
+	if len(enums) != 0 {
+		stdImports["from enum import Enum, IntEnum"] = true
+		typingImports["Literal"] = true
+	}
+	for _, table := range tables {
+		fields := make([]*pythonField, 0, len(table.Fields))
+		for _, field := range table.Fields {
+			f := &pythonField{
+				field:    field,
+				name:     field.Name,
+				optional: field.Nullable || field.AutoIncr,
+			}
+			if pythonKeywords[f.name] {
+				f.name += "_"
+			}
+			switch d.getFieldKind(ctx, field) {
+			case fieldKindInt, fieldKindUInt:
+				f.pyType = "int"
+			case fieldKindFloat:
+				f.pyType = "float"
+			case fieldKindDecimal:
+				f.pyType = "decimal.Decimal"
+				stdImports["import decimal"] = true
+			case fieldKindDateTime:
+				f.pyType = "datetime.datetime"
+				stdImports["import datetime"] = true
+			case fieldKindJSON:
+				f.pyType = "Any"
+				typingImports["Any"] = true
+			case fieldKindEnum:
+				e := fieldEnums[table.Name+"."+field.Name]
+				f.pyType = e.Name
+				if param.EnumAsLiteral {
+					f.pyType = e.Name + "Literal"
+				}
+			default:
+				f.pyType = "str"
+			}
+			if f.optional {
+				typingImports["Optional"] = true
+			}
+			fields = append(fields, f)
+		}
+		if style == PythonStylePydantic {
+			writePydanticModel(body, table, fields)
+		} else {
+			writePythonDataclass(body, table, fields)
+		}
+	}
+	if style == PythonStyleDataclass {
+		stdImports["import dataclasses"] = true
+	}
+	if len(typingImports) != 0 {
+		stdImports["from typing import "+strings.Join(sortedImports(typingImports), ", ")] = true
+	}
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("from __future__ import annotations\n\n")
+	// 标准库先import再from import，与isort的默认顺序一致
+	importLines := sortedImports(stdImports)
+	sort.SliceStable(importLines, func(i, j int) bool {
+		return strings.HasPrefix(importLines[i], "import ") && !strings.HasPrefix(importLines[j], "import ")
+	})
+	for _, importLine := range importLines {
+		buf.WriteString(importLine + "\n")
+	}
+	if style == PythonStylePydantic {
+		buf.WriteString("\nfrom pydantic import BaseModel, Field\n")
+	}
+	buf.WriteString(body.String())
+	return buf.String(), nil
+}
+
+// pythonDocstring 生成docstring，转义反斜杠以及双引号，避免产生转义序列或提前结束docstring
+func pythonDocstring(comment string) string {
+	comment = strings.ReplaceAll(comment, `\`, `\\`)
+	return `"""` + strings.ReplaceAll(comment, `"`, `\"`) + `"""`
+}
+
+// pythonComment 生成单行注释内容，换行等空白以及控制字符替换为空格
+func pythonComment(comment string) string {
+	return strings.Join(strings.FieldsFunc(comment, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+}
+
+// pythonQuote 转换为Python字符串字面量，控制字符使用\xHH转义，不会出现Go特有的\U等转义
+func pythonQuote(s string) string {
+	buf := strings.Builder{}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\x%02x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// writePythonEnum 生成枚举类，数字枚举为IntEnum，字符串枚举为str+Enum，
+// 同时生成枚举值->描述的映射以及枚举值的Literal类型，已下线的枚举值不出现在Literal中，
+// 枚举值均已下线时Literal包含所有枚举值，没有枚举值时Literal为枚举值的基础类型
+func writePythonEnum(buf *bytes.Buffer, e *genEnum) error {
+	base := "str, Enum"
+	if e.IsNum {
+		base = "IntEnum"
+	}
+	fmt.Fprintf(buf, "\n\nclass %s(%s):\n", e.Name, base)
+	if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+		fmt.Fprintf(buf, "    %s\n\n", pythonDocstring(comment))
+	}
+	literals := make([]string, 0, len(e.Enum.Values))
+	offlineLiterals := make([]string, 0, len(e.Enum.Values))
+	descs := make([]string, 0, len(e.Enum.Values))
+	for _, enumValue := range e.Enum.Values {
+		value := pythonQuote(enumValue.Value)
+		if e.IsNum {
+			if _, err := strconv.ParseInt(enumValue.Value, 10, 64); err != nil {
+				return fmt.Errorf("enum(%s) value(%s) is not a valid int", e.Name, enumValue.Value)
+			}
+			value = enumValue.Value
+		}
+		comment := pythonComment(genComment(enumValue.Desc, enumValue.Explain))
+		if enumValue.IsOffline() {
+			comment = strings.TrimSpace(comment + " 已下线")
+		}
+		if comment != "" {
+			fmt.Fprintf(buf, "    # %s\n", comment)
+		}
+		constName := strcase.ToScreamingSnake(enumValueName(e.Name, enumValue))
+		fmt.Fprintf(buf, "    %s = %s\n", constName, value)
+		descs = append(descs, fmt.Sprintf("    %s.%s: %s,\n", e.Name, constName, pythonQuote(enumValue.Desc)))
+		if enumValue.IsOffline() {
+			offlineLiterals = append(offlineLiterals, value)
+		} else {
+			literals = append(literals, value)
+		}
+	}
+	if len(e.Enum.Values) == 0 {
+		buf.WriteString("    pass\n")
+	}
+	fmt.Fprintf(buf, "\n\n%s_DESC = {\n%s}\n", strcase.ToScreamingSnake(e.Name), strings.Join(descs, ""))
+	if len(literals) == 0 {
+		literals = offlineLiterals
+	}
+	if len(literals) == 0 {
+		literalType := "str"
+		if e.IsNum {
+			literalType = "int"
+		}
+		fmt.Fprintf(buf, "\n%sLiteral = %s\n", e.Name, literalType)
+		return nil
+	}
+	fmt.Fprintf(buf, "\n%sLiteral = Literal[%s]\n", e.Name, strings.Join(literals, ", "))
+	return nil
+}
+
+func writePydanticModel(buf *bytes.Buffer, table *Table, fields []*pythonField) {
+	fmt.Fprintf(buf, "\n\nclass %s(BaseModel):\n", strcase.ToCamel(table.Name))
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "    %s\n\n", pythonDocstring(comment))
+	}
+	for _, f := range fields {
+		var args []string
+		pyType := f.pyType
+		if f.optional {
+			pyType = "Optional[" + pyType + "]"
+			args = append(args, "default=None")
+		}
+		if f.name != f.field.Name {
+			args = append(args, "alias="+pythonQuote(f.field.Name))
+		}
+		if f.field.CName != "" {
+			args = append(args, "description="+pythonQuote(f.field.CName))
+		}
+		fmt.Fprintf(buf, "    %s: %s = Field(%s)\n", f.name, pyType, strings.Join(args, ", "))
+	}
+	if len(fields) == 0 {
+		buf.WriteString("    pass\n")
+	}
+}
+
+// writePythonDataclass 生成dataclass，dataclass要求有默认值的字段在后，因此Optional字段排在最后
+func writePythonDataclass(buf *bytes.Buffer, table *Table, fields []*pythonField) {
+	fmt.Fprintf(buf, "\n\n@dataclasses.dataclass\nclass %s:\n", strcase.ToCamel(table.Name))
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "    %s\n\n", pythonDocstring(comment))
+	}
+	for _, optional := range []bool{false, true} {
+		for _, f := range fields {
+			if f.optional != optional {
+				continue
+			}
+			metadata := map[string]string{"column": f.field.Name}
+			if f.field.CName != "" {
+				metadata["description"] = f.field.CName
+			}
+			items := make([]string, 0, len(metadata))
+			for _, key := range []string{"column", "description"} {
+				if value, ok := metadata[key]; ok {
+					items = append(items, fmt.Sprintf("%s: %s", pythonQuote(key), pythonQuote(value)))
+				}
+			}
+			pyType := f.pyType
+			args := "metadata={" + strings.Join(items, ", ") + "}"
+			if f.optional {
+				pyType = "Optional[" + pyType + "]"
+				args = "default=None, " + args
+			}
+			fmt.Fprintf(buf, "    %s: %s = dataclasses.field(%s)\n", f.name, pyType, args)
+		}
+	}
+	if len(fields) == 0 {
+		buf.WriteString("    pass\n")
+	}
+}
@@ -0,0 +1,222 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToPython(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	tests := []struct {
+		name    string
+		tables  []*Table
+		param   *PythonParam
+		want    string
+		wantErr bool
+	}{
+		{"pydantic", newGenTestTables(), nil, `
+from __future__ import annotations
+
+import datetime
+from enum import Enum, IntEnum
+from typing import Any, Literal, Optional
+
+from pydantic import BaseModel, Field
+
+
+class TaskStatus(IntEnum):
+    """任务状态"""
+
+    # 待执行
+    WAIT = 1
+    # 已完成
+    FINISH = 2
+    # 已失败 已下线
+    FAIL = 3
+
+
+TASK_STATUS_DESC = {
+    TaskStatus.WAIT: "待执行",
+    TaskStatus.FINISH: "已完成",
+    TaskStatus.FAIL: "已失败",
+}
+
+TaskStatusLiteral = Literal[1, 2]
+
+
+class Phase(str, Enum):
+    """任务阶段"""
+
+    # 解析文件
+    PARSE_FILE = "parse_file"
+    # 发送文件
+    SEND_FILE = "send_file"
+
+
+PHASE_DESC = {
+    Phase.PARSE_FILE: "解析文件",
+    Phase.SEND_FILE: "发送文件",
+}
+
+PhaseLiteral = Literal["parse_file", "send_file"]
+
+
+class TTask(BaseModel):
+    """任务表"""
+
+    id: Optional[int] = Field(default=None, description="自增ID")
+    task_status: TaskStatus = Field(description="任务状态")
+    phase: Optional[Phase] = Field(default=None, description="任务阶段")
+    ext: Optional[Any] = Field(default=None, description="扩展信息")
+    create_time: datetime.datetime = Field(description="创建时间")
+
+
+class TSubTask(BaseModel):
+    """子任务表"""
+
+    id: Optional[int] = Field(default=None, description="自增ID")
+    task_id: int = Field(description="任务ID")
+    task_status: TaskStatus = Field(description="任务状态")
+    score: Optional[float] = Field(default=None, description="得分")
+`, false},
+		{"dataclass with literal", newGenTestTables(), &PythonParam{Style: PythonStyleDataclass, EnumAsLiteral: true}, `
+from __future__ import annotations
+
+import dataclasses
+import datetime
+from enum import Enum, IntEnum
+from typing import Any, Literal, Optional
+
+
+class TaskStatus(IntEnum):
+    """任务状态"""
+
+    # 待执行
+    WAIT = 1
+    # 已完成
+    FINISH = 2
+    # 已失败 已下线
+    FAIL = 3
+
+
+TASK_STATUS_DESC = {
+    TaskStatus.WAIT: "待执行",
+    TaskStatus.FINISH: "已完成",
+    TaskStatus.FAIL: "已失败",
+}
+
+TaskStatusLiteral = Literal[1, 2]
+
+
+class Phase(str, Enum):
+    """任务阶段"""
+
+    # 解析文件
+    PARSE_FILE = "parse_file"
+    # 发送文件
+    SEND_FILE = "send_file"
+
+
+PHASE_DESC = {
+    Phase.PARSE_FILE: "解析文件",
+    Phase.SEND_FILE: "发送文件",
+}
+
+PhaseLiteral = Literal["parse_file", "send_file"]
+
+
+@dataclasses.dataclass
+class TTask:
+    """任务表"""
+
+    task_status: TaskStatusLiteral = dataclasses.field(metadata={"column": "task_status", "description": "任务状态"})
+    create_time: datetime.datetime = dataclasses.field(metadata={"column": "create_time", "description": "创建时间"})
+    id: Optional[int] = dataclasses.field(default=None, metadata={"column": "id", "description": "自增ID"})
+    phase: Optional[PhaseLiteral] = dataclasses.field(default=None, metadata={"column": "phase", "description": "任务阶段"})
+    ext: Optional[Any] = dataclasses.field(default=None, metadata={"column": "ext", "description": "扩展信息"})
+
+
+@dataclasses.dataclass
+class TSubTask:
+    """子任务表"""
+
+    task_id: int = dataclasses.field(metadata={"column": "task_id", "description": "任务ID"})
+    task_status: TaskStatusLiteral = dataclasses.field(metadata={"column": "task_status", "description": "任务状态"})
+    id: Optional[int] = dataclasses.field(default=None, metadata={"column": "id", "description": "自增ID"})
+    score: Optional[float] = dataclasses.field(default=None, metadata={"column": "score", "description": "得分"})
+`, false},
+		// 枚举值均已下线时Literal包含所有枚举值，字符串按Python的规则转义
+		{"all offline", newJVMEnumTestTables(), nil, `
+from __future__ import annotations
+
+from enum import Enum, IntEnum
+from typing import Literal
+
+from pydantic import BaseModel, Field
+
+
+class Tag(str, Enum):
+    # 价格"$x"\ 已下线
+    PRICE = "a\\u0022$b"
+
+
+TAG_DESC = {
+    Tag.PRICE: "价格\"$x\"\\\n\x01",
+}
+
+TagLiteral = Literal["a\\u0022$b"]
+
+
+class TItem(BaseModel):
+    id: int = Field()
+    kind: str = Field()
+    tag: Tag = Field()
+`, false},
+		{"unknown style", newGenTestTables(), &PythonParam{Style: "attrs"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.ToPython(ctx, tt.tables, tt.param)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultMetaCenter.ToPython() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := strings.TrimPrefix(tt.want, "\n"); got != want {
+				t.Errorf("DefaultMetaCenter.ToPython() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_writePythonEnum_Empty(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := writePythonEnum(buf, &genEnum{Name: "Kind", Enum: &Enum{ID: 1}}); err != nil {
+		t.Fatalf("writePythonEnum() error = %v", err)
+	}
+	want := "\n\nclass Kind(str, Enum):\n    pass\n\n\nKIND_DESC = {\n}\n\nKindLiteral = str\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writePythonEnum() = %q, want %q", got, want)
+	}
+}
+
+func Test_pythonQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{"中文\U0001F600", "\"中文\U0001F600\""},
+		{`a"b\c`, `"a\"b\\c"`},
+		{"a\nb\r\tc", `"a\nb\r\tc"`},
+		{"\x00\x1f\x7f", `"\x00\x1f\x7f"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := pythonQuote(tt.s); got != tt.want {
+				t.Errorf("pythonQuote() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
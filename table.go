@@ -24,6 +24,7 @@ type Table struct {
 			MultiIndex       bool   `json:"multi_index"`
 			IndexFieldID     int    `json:"index_field_id"`
 			IndexMode        int    `json:"index_mode"`
+			HashBuckets      int    `json:"hash_buckets"`
 			MaxResultWindow  int    `json:"max_result_window"`
 			NumberOfShards   int    `json:"number_of_shards"`
 			NumberOfReplicas int    `json:"number_of_replicas"`
@@ -32,6 +33,36 @@ type Table struct {
 	} `json:"es_config"`
 	Fields     []*Field          `json:"-"`
 	NameFields map[string]*Field `json:"-"`
+	// TableFields 字段ID->表和字段的关联，包含唯一、加密以及关联表等信息
+	TableFields map[int]*TableField `json:"-"`
+}
+
+// GetFieldByID 根据字段ID获取表中的字段配置，不存在时返回nil
+func (t *Table) GetFieldByID(id int) *Field {
+	for _, field := range t.Fields {
+		if field.ID == id {
+			return field
+		}
+	}
+	return nil
+}
+
+// GetFieldByName 根据字段英文名获取表中的字段配置，不存在时返回nil
+func (t *Table) GetFieldByName(name string) *Field {
+	if t.NameFields != nil {
+		return t.NameFields[name]
+	}
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// GetTableField 根据字段ID获取表和字段的关联，不存在时返回nil
+func (t *Table) GetTableField(fieldID int) *TableField {
+	return t.TableFields[fieldID]
 }
 
 // TableGetter 表配置获取接口
@@ -0,0 +1,94 @@
+package metacenter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// newGenTestTables 生成器测试使用的表配置，类型ID对应DefaultDataTypeGetter
+func newGenTestTables() []*Table {
+	status := &Enum{
+		ID:         1,
+		CName:      "任务状态",
+		DataTypeID: 1,
+		Values: []*EnumValue{
+			{ID: 1, EnumID: 1, EName: "wait", Desc: "待执行", Value: "1"},
+			{ID: 2, EnumID: 1, EName: "finish", Desc: "已完成", Value: "2"},
+			{ID: 3, EnumID: 1, EName: "fail", Desc: "已失败", Value: "3", Status: EnumValueStatusOffline},
+		},
+	}
+	phase := &Enum{
+		ID:         2,
+		CName:      "任务阶段",
+		DataTypeID: 3,
+		Values: []*EnumValue{
+			{ID: 4, EnumID: 2, EName: "parse_file", Desc: "解析文件", Value: "parse_file"},
+			{ID: 5, EnumID: 2, EName: "send_file", Desc: "发送文件", Value: "send_file"},
+		},
+	}
+	task := &Table{
+		ID:    1,
+		Name:  "t_task",
+		CName: "任务表",
+		Fields: []*Field{
+			{ID: 1, Name: "id", CName: "自增ID", Type: 2, IsPK: true, AutoIncr: true},
+			{ID: 2, Name: "task_status", CName: "任务状态", Type: 6, EnumID: 1, Enum: status},
+			{ID: 3, Name: "phase", CName: "任务阶段", Type: 6, EnumID: 2, Enum: phase, Nullable: true},
+			{ID: 4, Name: "ext", CName: "扩展信息", Type: 7, Explain: "json对象", Nullable: true},
+			{ID: 5, Name: "create_time", CName: "创建时间", Type: 5},
+		},
+	}
+	subTask := &Table{
+		ID:    2,
+		Name:  "t_sub_task",
+		CName: "子任务表",
+		Fields: []*Field{
+			{ID: 1, Name: "id", CName: "自增ID", Type: 2, IsPK: true, AutoIncr: true},
+			{ID: 6, Name: "task_id", CName: "任务ID", Type: 2},
+			{ID: 2, Name: "task_status", CName: "任务状态", Type: 6, EnumID: 1, Enum: status},
+			{ID: 7, Name: "score", CName: "得分", Type: 4, Nullable: true},
+		},
+		TableFields: map[int]*TableField{
+			6: {TableID: 2, FieldID: 6, RefTableID: 1},
+		},
+	}
+	return []*Table{task, subTask}
+}
+
+// runGeneratedGoTests 将生成的文件以及extra(路径->内容，如测试文件、go.mod)写入临时目录，执行go vet以及go test
+// 没有Go工具链或-short时跳过
+func runGeneratedGoTests(t *testing.T, fileSet *GeneratedFileSet, extra map[string]string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skip running generated code in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not found")
+	}
+	dir := t.TempDir()
+	write := func(path string, content []byte) {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatalf("mkdir fail: %v", err)
+		}
+		if err := os.WriteFile(path, content, 0666); err != nil {
+			t.Fatalf("write file fail: %v", err)
+		}
+	}
+	for _, file := range fileSet.Files() {
+		write(file.Path, file.Content)
+	}
+	for path, content := range extra {
+		write(path, []byte(content))
+	}
+	for _, args := range [][]string{{"vet", "./..."}, {"test", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off", "GOTOOLCHAIN=local")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s fail: %v\n%s", args[0], err, out)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package metacenter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// thriftMaxFieldID thrift字段ID为i16，最大值为32767
+const thriftMaxFieldID = 1<<15 - 1
+
+// ThriftParam 生成thrift文件可指定的参数
+type ThriftParam struct {
+	// Namespaces 语言->命名空间，如go->metacenter.task
+	Namespaces map[string]string
+	// RequiredNonNull 不可为空的字段使用required，默认不指定，可为空的字段总是optional
+	RequiredNonNull bool
+}
+
+// ToThrift 将表以及表使用到的枚举转换为thrift struct以及enum定义
+// 字段ID使用Field.ID，保证字段增删后ID稳定；整数以及日期时间(毫秒时间戳)使用i64
+func (d *DefaultMetaCenter) ToThrift(ctx context.Context, tables []*Table, param *ThriftParam) (string, error) {
+	if param == nil {
+		param = &ThriftParam{}
+	}
+	enums, fieldEnums := d.collectEnums(ctx, tables)
+	buf := bytes.NewBuffer(nil)
+	if len(param.Namespaces) != 0 {
+		langs := make([]string, 0, len(param.Namespaces))
+		for lang := range param.Namespaces {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			fmt.Fprintf(buf, "namespace %s %s\n", lang, param.Namespaces[lang])
+		}
+		buf.WriteString("\n")
+	}
+	for _, e := range enums {
+		if err := writeThriftEnum(buf, e); err != nil {
+			return "", err
+		}
+	}
+	for _, table := range tables {
+		if err := d.writeThriftStruct(ctx, buf, table, fieldEnums, param); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n") + "\n", nil
+}
+
+func (d *DefaultMetaCenter) writeThriftStruct(ctx context.Context, buf *bytes.Buffer, table *Table,
+	fieldEnums map[string]*genEnum, param *ThriftParam) error {
+	if comment := genComment(table.CName, ""); comment != "" {
+		fmt.Fprintf(buf, "// %s %s\n", strcase.ToCamel(table.Name), comment)
+	}
+	fmt.Fprintf(buf, "struct %s {\n", strcase.ToCamel(table.Name))
+	ids := make(map[int]string)
+	for _, field := range table.Fields {
+		if field.ID <= 0 || field.ID > thriftMaxFieldID {
+			return fmt.Errorf("field(%s) of table(%s) has invalid thrift field id(%d)", field.Name, table.Name, field.ID)
+		}
+		if name, ok := ids[field.ID]; ok {
+			return fmt.Errorf("field(%s) and field(%s) of table(%s) have the same id(%d)", name, field.Name, table.Name, field.ID)
+		}
+		ids[field.ID] = field.Name
+		var thriftType string
+		switch d.getFieldKind(ctx, field) {
+		case fieldKindInt, fieldKindUInt, fieldKindDateTime:
+			thriftType = "i64"
+		case fieldKindFloat:
+			thriftType = "double"
+		case fieldKindEnum:
+			thriftType = fieldEnums[table.Name+"."+field.Name].Name
+		default:
+			// decimal以及json使用字符串
+			thriftType = "string"
+		}
+		requiredness := ""
+		if field.Nullable {
+			requiredness = "optional "
+		} else if param.RequiredNonNull {
+			requiredness = "required "
+		}
+		if comment := genComment(field.CName, field.Explain); comment != "" {
+			fmt.Fprintf(buf, "  // %s\n", comment)
+		}
+		fmt.Fprintf(buf, "  %d: %s%s %s,\n", field.ID, requiredness, thriftType, strcase.ToSnake(field.Name))
+	}
+	buf.WriteString("}\n\n")
+	return nil
+}
+
+// writeThriftEnum 生成thrift枚举，值名称为EName的大写下划线形式
+// 数字枚举使用枚举值，字符串枚举使用EnumValue.ID(为0时使用序号)
+func writeThriftEnum(buf *bytes.Buffer, e *genEnum) error {
+	if comment := genComment(e.Enum.CName, e.Enum.Explain); comment != "" {
+		fmt.Fprintf(buf, "// %s %s\n", e.Name, comment)
+	}
+	fmt.Fprintf(buf, "enum %s {\n", e.Name)
+	numbers := make(map[int64]bool)
+	for i, enumValue := range e.Enum.Values {
+		number := int64(enumValue.ID)
+		if e.IsNum {
+			var err error
+			if number, err = strconv.ParseInt(enumValue.Value, 10, 32); err != nil {
+				return fmt.Errorf("enum(%s) value(%s) is not a valid thrift enum value", e.Name, enumValue.Value)
+			}
+		} else if number <= 0 {
+			number = int64(i + 1)
+		}
+		if numbers[number] {
+			return fmt.Errorf("enum(%s) has duplicate value(%d)", e.Name, number)
+		}
+		numbers[number] = true
+		comment := genComment(enumValue.Desc, enumValue.Explain)
+		if !e.IsNum {
+			comment = strings.TrimSpace(enumValue.Value + " " + comment)
+		}
+		if comment != "" {
+			fmt.Fprintf(buf, "  // %s\n", comment)
+		}
+		fmt.Fprintf(buf, "  %s = %d,\n", strcase.ToScreamingSnake(enumValueName(e.Name, enumValue)), number)
+	}
+	buf.WriteString("}\n\n")
+	return nil
+}
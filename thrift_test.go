@@ -0,0 +1,74 @@
+package metacenter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefaultMetaCenter_ToThrift(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewDefaultDataTypeGetter()))
+	want := `namespace go metacenter.task
+namespace java com.imqishi.task
+
+// TaskStatus 任务状态
+enum TaskStatus {
+  // 待执行
+  WAIT = 1,
+  // 已完成
+  FINISH = 2,
+  // 已失败
+  FAIL = 3,
+}
+
+// Phase 任务阶段
+enum Phase {
+  // parse_file 解析文件
+  PARSE_FILE = 4,
+  // send_file 发送文件
+  SEND_FILE = 5,
+}
+
+// TTask 任务表
+struct TTask {
+  // 自增ID
+  1: required i64 id,
+  // 任务状态
+  2: required TaskStatus task_status,
+  // 任务阶段
+  3: optional Phase phase,
+  // 扩展信息 json对象
+  4: optional string ext,
+  // 创建时间
+  5: required i64 create_time,
+}
+
+// TSubTask 子任务表
+struct TSubTask {
+  // 自增ID
+  1: required i64 id,
+  // 任务ID
+  6: required i64 task_id,
+  // 任务状态
+  2: required TaskStatus task_status,
+  // 得分
+  7: optional double score,
+}
+`
+	got, err := d.ToThrift(ctx, newGenTestTables(), &ThriftParam{
+		Namespaces:      map[string]string{"go": "metacenter.task", "java": "com.imqishi.task"},
+		RequiredNonNull: true,
+	})
+	if err != nil {
+		t.Fatalf("DefaultMetaCenter.ToThrift() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("DefaultMetaCenter.ToThrift() = %v, want %v", got, want)
+	}
+
+	tables := newGenTestTables()
+	tables[0].Fields[0].ID = 40000
+	if _, err := d.ToThrift(ctx, tables, nil); err == nil {
+		t.Errorf("DefaultMetaCenter.ToThrift() with field id out of i16 range should fail")
+	}
+}
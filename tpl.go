@@ -0,0 +1,59 @@
+package metacenter
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+)
+
+// defaultTplFiles 内置模板，使模块作为依赖引用时也可以直接使用
+//
+//go:embed tpl_files/*.tpl
+var defaultTplFiles embed.FS
+
+// defaultTplDir 内置模板所在目录
+const defaultTplDir = "tpl_files"
+
+// tplFileExt 模板文件扩展名，按名称选择模板时可省略
+const tplFileExt = ".tpl"
+
+// DefaultTplFS 获取内置模板文件系统，文件名为模板名+.tpl，如model.tpl/const.tpl/ts.tpl
+func DefaultTplFS() fs.FS {
+	sub, err := fs.Sub(defaultTplFiles, defaultTplDir)
+	if err != nil {
+		// 目录由go:embed保证存在
+		panic(err)
+	}
+	return sub
+}
+
+// readTpl 读取模板内容，返回模板来源用于错误提示
+// 指定TplFilePath时从文件系统路径读取，否则从TplFS(默认为内置模板)中读取TplName(默认为Name)对应的模板
+func (p *GenerateGoFilesParam) readTpl() (string, []byte, error) {
+	if p.TplFilePath != "" {
+		body, err := os.ReadFile(p.TplFilePath)
+		if err != nil {
+			return p.TplFilePath, nil, errors.Wrapf(err, "read TplFilePath(%s) fail", p.TplFilePath)
+		}
+		return p.TplFilePath, body, nil
+	}
+	tplFS := p.TplFS
+	if tplFS == nil {
+		tplFS = DefaultTplFS()
+	}
+	name := p.TplName
+	if name == "" {
+		name = p.Name
+	}
+	if path.Ext(name) == "" {
+		name += tplFileExt
+	}
+	body, err := fs.ReadFile(tplFS, name)
+	if err != nil {
+		return name, nil, errors.Wrapf(err, "read template(%s) fail", name)
+	}
+	return name, body, nil
+}
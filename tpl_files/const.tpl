@@ -17,7 +17,7 @@ const (
     {{- end}}
 )
 
-{{if .HasEnum}}
+{{if and .HasEnum (not .SharedEnum)}}
 // 表枚举字段定义
     {{- range $index, $field := .Fields}}
     {{if .IsEnum}}
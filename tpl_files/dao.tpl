@@ -0,0 +1,154 @@
+{{- $dao := printf "%sDAO" .Table.VarName -}}
+{{- $columns := printf "%sColumns" (lowerCamel .Table.Name) -}}
+// Package {{.PkgName}} {{.PkgName}}的数据访问层
+package {{.PkgName}}
+
+// {{$columns}} {{.Table.Name}}表的所有字段
+var {{$columns}} = []string{ {{- join ", " (fieldValues .Fields "Name" | quoteAll) -}} }
+
+// {{$columns}}Insert 插入时使用的字段，不包含自增字段
+var {{$columns}}Insert = []string{ {{- join ", " (fieldValues .InsertFields "Name" | quoteAll) -}} }
+{{- if .PKFields}}
+
+// {{$columns}}PK 主键字段
+var {{$columns}}PK = []string{ {{- join ", " (fieldValues .PKFields "Name" | quoteAll) -}} }
+{{- end}}
+{{- if and .PKFields .UpdateFields}}
+
+// {{$columns}}Update 按主键更新时使用的字段
+var {{$columns}}Update = []string{ {{- join ", " (fieldValues .UpdateFields "Name" | quoteAll) -}} }
+{{- end}}
+
+// {{$dao}} {{.Table.CName}}的数据访问对象
+type {{$dao}} struct {
+    db      DB
+    dialect Dialect
+    table   string
+}
+
+// New{{$dao}} 实例化{{.Table.CName}}的数据访问对象，db可以为*sql.DB或*sql.Tx
+func New{{$dao}}(db DB, dialect Dialect) *{{$dao}} {
+    return &{{$dao}}{db: db, dialect: dialect, table: dialect.Quote({{quote .Table.Name}})}
+}
+
+// insertArgs 插入时的参数，顺序与{{$columns}}Insert一致
+func (*{{$dao}}) insertArgs(m *{{.ModelType}}) []interface{} {
+    return []interface{}{ {{- range .InsertFields}}m.{{.VarName}}, {{end -}} }
+}
+
+// scanDest 查询时的Scan目标，顺序与{{$columns}}一致
+func (*{{$dao}}) scanDest(m *{{.ModelType}}) []interface{} {
+    return []interface{}{ {{- range .Fields}}&m.{{.VarName}}, {{end -}} }
+}
+
+// Insert 插入一条记录{{if .AutoIncr}}，插入后回填自增字段{{.AutoIncr.VarName}}{{end}}
+func (dao *{{$dao}}) Insert(ctx context.Context, m *{{.ModelType}}) error {
+    query := "INSERT INTO " + dao.table + " (" + quoteNames(dao.dialect, {{$columns}}Insert) + ") VALUES " +
+        placeholders(dao.dialect, 1, len({{$columns}}Insert))
+{{- if .AutoIncr}}
+    if returning := dao.dialect.Returning({{quote .AutoIncr.Name}}); returning != "" {
+        rows, err := dao.db.QueryContext(ctx, query+" "+returning, dao.insertArgs(m)...)
+        if err != nil {
+            return err
+        }
+        defer rows.Close()
+        if !rows.Next() {
+            if err := rows.Err(); err != nil {
+                return err
+            }
+            return sql.ErrNoRows
+        }
+        return rows.Scan(&m.{{.AutoIncr.VarName}})
+    }
+    result, err := dao.db.ExecContext(ctx, query, dao.insertArgs(m)...)
+    if err != nil {
+        return err
+    }
+    id, err := result.LastInsertId()
+    if err != nil {
+        return err
+    }
+    m.{{.AutoIncr.VarName}} = {{.AutoIncr.Type}}(id)
+    return nil
+{{- else}}
+    _, err := dao.db.ExecContext(ctx, query, dao.insertArgs(m)...)
+    return err
+{{- end}}
+}
+
+// BatchInsert 批量插入多条记录，返回影响的行数{{if .AutoIncr}}，不回填自增字段{{end}}
+// 参数个数超过maxBatchArgs时分多条SQL执行，出错时返回已插入的行数，需要原子性时db传入*sql.Tx
+func (dao *{{$dao}}) BatchInsert(ctx context.Context, ms []*{{.ModelType}}) (int64, error) {
+    size := batchRows(len({{$columns}}Insert))
+    var total int64
+    for len(ms) != 0 {
+        batch := ms
+        if len(batch) > size {
+            batch = batch[:size]
+        }
+        ms = ms[len(batch):]
+        args := make([]interface{}, 0, len(batch)*len({{$columns}}Insert))
+        for _, m := range batch {
+            args = append(args, dao.insertArgs(m)...)
+        }
+        query := "INSERT INTO " + dao.table + " (" + quoteNames(dao.dialect, {{$columns}}Insert) + ") VALUES " +
+            placeholders(dao.dialect, len(batch), len({{$columns}}Insert))
+        affected, err := execAffected(ctx, dao.db, query, args...)
+        total += affected
+        if err != nil {
+            return total, err
+        }
+    }
+    return total, nil
+}
+{{- if and .PKFields .UpdateFields}}
+
+// Update 按主键更新所有非主键字段，返回影响的行数
+func (dao *{{$dao}}) Update(ctx context.Context, m *{{.ModelType}}) (int64, error) {
+    query := "UPDATE " + dao.table + " SET " + assignments(dao.dialect, 1, {{$columns}}Update, ", ") +
+        " WHERE " + assignments(dao.dialect, len({{$columns}}Update)+1, {{$columns}}PK, " AND ")
+    return execAffected(ctx, dao.db, query,
+        {{- range .UpdateFields}} m.{{.VarName}},{{end}}{{range .PKFields}} m.{{.VarName}},{{end}})
+}
+{{- end}}
+{{- if .PKFields}}
+
+// Delete 按主键删除，返回影响的行数
+func (dao *{{$dao}}) Delete(ctx context.Context, {{pkParams .PKFields}}) (int64, error) {
+    query := "DELETE FROM " + dao.table + " WHERE " + assignments(dao.dialect, 1, {{$columns}}PK, " AND ")
+    return execAffected(ctx, dao.db, query, {{range .PKFields}}{{goVar .Name}}, {{end}})
+}
+
+// GetByPK 按主键查询，不存在时返回sql.ErrNoRows
+func (dao *{{$dao}}) GetByPK(ctx context.Context, {{pkParams .PKFields}}) (*{{.ModelType}}, error) {
+    return dao.queryOne(ctx, assignments(dao.dialect, 1, {{$columns}}PK, " AND "), {{range .PKFields}}{{goVar .Name}}, {{end}})
+}
+{{- end}}
+{{- range .UniqueFields}}
+
+// FindBy{{.VarName}} 按唯一字段{{.CName}}查询，不存在时返回sql.ErrNoRows
+func (dao *{{$dao}}) FindBy{{.VarName}}(ctx context.Context, {{goVar .Name}} {{.Type}}) (*{{$.ModelType}}, error) {
+    return dao.queryOne(ctx, assignments(dao.dialect, 1, []string{ {{- quote .Name -}} }, " AND "), {{goVar .Name}})
+}
+{{- end}}
+
+// queryOne 按条件查询一条记录，不存在时返回sql.ErrNoRows
+func (dao *{{$dao}}) queryOne(ctx context.Context, where string, args ...interface{}) (*{{.ModelType}}, error) {
+    query := "SELECT " + quoteNames(dao.dialect, {{$columns}}) + " FROM " + dao.table + " WHERE " + where
+    rows, err := dao.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    if !rows.Next() {
+        if err := rows.Err(); err != nil {
+            return nil, err
+        }
+        return nil, sql.ErrNoRows
+    }
+    m := &{{.ModelType}}{}
+    if err := rows.Scan(dao.scanDest(m)...); err != nil {
+        return nil, err
+    }
+    return m, rows.Err()
+}
@@ -0,0 +1,105 @@
+// Package {{.PkgName}} 数据访问层，基于database/sql，通过Dialect适配不同的数据库
+package {{.PkgName}}
+
+// maxBatchArgs 一条SQL中参数个数的上限，MySQL以及PostgreSQL均不能超过65535，批量插入超过时分多条SQL执行
+var maxBatchArgs = 65535
+
+// batchRows 每条批量插入SQL最多插入的行数，每行columns个参数
+func batchRows(columns int) int {
+    if columns == 0 || columns >= maxBatchArgs {
+        return 1
+    }
+    return maxBatchArgs / columns
+}
+
+// DB 执行SQL的数据库连接，*sql.DB、*sql.Tx以及*sql.Conn均实现了该接口
+type DB interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Dialect SQL方言，屏蔽不同数据库在标识符引用、占位符以及获取自增主键上的差异
+type Dialect interface {
+    // Quote 引用表名或字段名
+    Quote(name string) string
+    // Placeholder 第n个参数的占位符，n从1开始
+    Placeholder(n int) string
+    // Returning 插入时返回自增字段的子句，为空时通过sql.Result.LastInsertId获取
+    Returning(column string) string
+}
+
+// MySQLDialect MySQL方言
+type MySQLDialect struct{}
+
+// Quote 使用反引号引用
+func (MySQLDialect) Quote(name string) string {
+    return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Placeholder 占位符为?
+func (MySQLDialect) Placeholder(int) string {
+    return "?"
+}
+
+// Returning 通过LastInsertId获取自增字段
+func (MySQLDialect) Returning(string) string {
+    return ""
+}
+
+// PostgresDialect PostgreSQL方言
+type PostgresDialect struct{}
+
+// Quote 使用双引号引用
+func (PostgresDialect) Quote(name string) string {
+    return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Placeholder 占位符为$n
+func (PostgresDialect) Placeholder(n int) string {
+    return "$" + strconv.Itoa(n)
+}
+
+// Returning 通过RETURNING子句获取自增字段
+func (d PostgresDialect) Returning(column string) string {
+    return "RETURNING " + d.Quote(column)
+}
+
+// quoteNames 引用并使用逗号拼接字段名
+func quoteNames(dialect Dialect, names []string) string {
+    quoted := make([]string, 0, len(names))
+    for _, name := range names {
+        quoted = append(quoted, dialect.Quote(name))
+    }
+    return strings.Join(quoted, ", ")
+}
+
+// placeholders 生成rows组(?, ?)形式的占位符，每组columns个，参数编号从1开始
+func placeholders(dialect Dialect, rows, columns int) string {
+    groups := make([]string, 0, rows)
+    for i := 0; i < rows; i++ {
+        group := make([]string, 0, columns)
+        for j := 1; j <= columns; j++ {
+            group = append(group, dialect.Placeholder(i*columns+j))
+        }
+        groups = append(groups, "("+strings.Join(group, ", ")+")")
+    }
+    return strings.Join(groups, ", ")
+}
+
+// assignments 生成`a` = ?形式的条件或赋值并使用sep拼接，参数编号从start开始
+func assignments(dialect Dialect, start int, names []string, sep string) string {
+    items := make([]string, 0, len(names))
+    for i, name := range names {
+        items = append(items, dialect.Quote(name)+" = "+dialect.Placeholder(start+i))
+    }
+    return strings.Join(items, sep)
+}
+
+// execAffected 执行SQL并返回影响的行数
+func execAffected(ctx context.Context, db DB, query string, args ...interface{}) (int64, error) {
+    result, err := db.ExecContext(ctx, query, args...)
+    if err != nil {
+        return 0, err
+    }
+    return result.RowsAffected()
+}
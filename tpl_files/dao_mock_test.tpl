@@ -0,0 +1,190 @@
+package {{.PkgName}}
+
+// mockExpectation 预期执行的一条SQL以及返回结果
+type mockExpectation struct {
+    query        string
+    args         []interface{}
+    columns      []string
+    rows         [][]interface{}
+    lastInsertID int64
+    rowsAffected int64
+    err          error
+}
+
+// WillReturnResult 设置Exec的返回结果
+func (e *mockExpectation) WillReturnResult(lastInsertID, rowsAffected int64) *mockExpectation {
+    e.lastInsertID, e.rowsAffected = lastInsertID, rowsAffected
+    return e
+}
+
+// WillReturnRows 设置Query的返回结果，rows中的值会通过driver.Valuer转换
+func (e *mockExpectation) WillReturnRows(columns []string, rows ...[]interface{}) *mockExpectation {
+    e.columns, e.rows = columns, rows
+    return e
+}
+
+// WillReturnError 设置返回的错误
+func (e *mockExpectation) WillReturnError(err error) *mockExpectation {
+    e.err = err
+    return e
+}
+
+// mockDB sqlmock风格的数据库替身，按顺序校验执行的SQL以及参数并返回预设的结果，不依赖真实数据库
+type mockDB struct {
+    t            *testing.T
+    expectations []*mockExpectation
+}
+
+// newMockDB 创建数据库替身，测试结束时校验所有预期的SQL均已执行
+func newMockDB(t *testing.T) (*sql.DB, *mockDB) {
+    mock := &mockDB{t: t}
+    db := sql.OpenDB(mock)
+    t.Cleanup(func() {
+        _ = db.Close()
+        for _, e := range mock.expectations {
+            t.Errorf("expected query was not executed: %s", e.query)
+        }
+    })
+    return db, mock
+}
+
+// ExpectExec 预期执行的Exec
+func (m *mockDB) ExpectExec(query string, args ...interface{}) *mockExpectation {
+    e := &mockExpectation{query: query, args: args}
+    m.expectations = append(m.expectations, e)
+    return e
+}
+
+// ExpectQuery 预期执行的Query
+func (m *mockDB) ExpectQuery(query string, args ...interface{}) *mockExpectation {
+    return m.ExpectExec(query, args...)
+}
+
+// next 校验并取出下一条预期的SQL
+func (m *mockDB) next(query string, args []driver.NamedValue) (*mockExpectation, error) {
+    if len(m.expectations) == 0 {
+        return nil, fmt.Errorf("unexpected query: %s", query)
+    }
+    e := m.expectations[0]
+    m.expectations = m.expectations[1:]
+    if e.query != query {
+        return nil, fmt.Errorf("query = %s, want %s", query, e.query)
+    }
+    values := make([]interface{}, 0, len(args))
+    for _, arg := range args {
+        values = append(values, arg.Value)
+    }
+    // 预期的参数与实际参数一样转换为driver.Value后比较
+    want := make([]interface{}, 0, len(e.args))
+    for _, arg := range e.args {
+        value, err := driver.DefaultParameterConverter.ConvertValue(arg)
+        if err != nil {
+            return nil, fmt.Errorf("query(%s) convert expected arg(%v) fail: %v", query, arg, err)
+        }
+        want = append(want, value)
+    }
+    if len(values) != len(want) || (len(values) != 0 && !reflect.DeepEqual(values, want)) {
+        return nil, fmt.Errorf("query(%s) args = %v, want %v", query, values, want)
+    }
+    return e, e.err
+}
+
+// Connect 实现driver.Connector
+func (m *mockDB) Connect(context.Context) (driver.Conn, error) {
+    return &mockConn{db: m}, nil
+}
+
+// Driver 实现driver.Connector
+func (m *mockDB) Driver() driver.Driver {
+    return mockDriver{}
+}
+
+type mockDriver struct{}
+
+// Open 实现driver.Driver，只能通过sql.OpenDB使用
+func (mockDriver) Open(string) (driver.Conn, error) {
+    return nil, errors.New("mock driver should be used with sql.OpenDB")
+}
+
+type mockConn struct {
+    db *mockDB
+}
+
+func (c *mockConn) Prepare(query string) (driver.Stmt, error) {
+    return nil, fmt.Errorf("prepare is not supported: %s", query)
+}
+
+func (c *mockConn) Close() error {
+    return nil
+}
+
+func (c *mockConn) Begin() (driver.Tx, error) {
+    return nil, errors.New("transaction is not supported")
+}
+
+// CheckNamedValue 与database/sql的默认行为一致，driver.Valuer以及指针等参数转换为driver.Value，转换失败时返回错误
+func (c *mockConn) CheckNamedValue(nv *driver.NamedValue) error {
+    value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+    if err != nil {
+        return err
+    }
+    nv.Value = value
+    return nil
+}
+
+func (c *mockConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+    e, err := c.db.next(query, args)
+    if err != nil {
+        return nil, err
+    }
+    return mockResult{lastInsertID: e.lastInsertID, rowsAffected: e.rowsAffected}, nil
+}
+
+func (c *mockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+    e, err := c.db.next(query, args)
+    if err != nil {
+        return nil, err
+    }
+    return &mockRows{columns: e.columns, rows: e.rows}, nil
+}
+
+type mockResult struct {
+    lastInsertID int64
+    rowsAffected int64
+}
+
+func (r mockResult) LastInsertId() (int64, error) {
+    return r.lastInsertID, nil
+}
+
+func (r mockResult) RowsAffected() (int64, error) {
+    return r.rowsAffected, nil
+}
+
+type mockRows struct {
+    columns []string
+    rows    [][]interface{}
+}
+
+func (r *mockRows) Columns() []string {
+    return r.columns
+}
+
+func (r *mockRows) Close() error {
+    return nil
+}
+
+// Next 实现driver.Rows，实现了driver.Valuer的值转换为driver.Value，其他值原样返回
+func (r *mockRows) Next(dest []driver.Value) error {
+    if len(r.rows) == 0 {
+        return io.EOF
+    }
+    for i, value := range r.rows[0] {
+        if converted, err := driver.DefaultParameterConverter.ConvertValue(value); err == nil {
+            value = converted
+        }
+        dest[i] = value
+    }
+    r.rows = r.rows[1:]
+    return nil
+}
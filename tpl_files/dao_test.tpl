@@ -0,0 +1,161 @@
+{{- $dao := printf "%sDAO" .Table.VarName -}}
+{{- $table := .Table.Name -}}
+package {{.PkgName}}
+
+// newTest{{.Table.VarName}} 测试使用的{{.Table.CName}}记录，枚举字段取第一个枚举值
+func newTest{{.Table.VarName}}() *{{.ModelType}} {
+    return &{{.ModelType}}{
+    {{- range $field := .Fields}}
+        {{- if and .IsEnum .EnumValues}}
+            {{- with index .EnumValues 0}}
+        {{$field.VarName}}: {{$field.Type}}({{if $field.IsNum}}{{.Value}}{{else}}{{quote .Value}}{{end}}),
+            {{- end}}
+        {{- end}}
+    {{- end}}
+    }
+}
+
+func Test{{$dao}}_Insert(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{.Table.VarName}}()
+    mock.ExpectExec("INSERT INTO `{{$table}}` ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}`{{.Name}}`{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}})",
+        {{- range .InsertFields}} m.{{.VarName}},{{end}}).WillReturnResult(1, 1)
+    if err := New{{$dao}}(db, MySQLDialect{}).Insert(context.Background(), m); err != nil {
+        t.Fatalf("{{$dao}}.Insert() error = %v", err)
+    }
+{{- if .AutoIncr}}
+    if m.{{.AutoIncr.VarName}} != 1 {
+        t.Errorf("{{$dao}}.Insert() {{.AutoIncr.VarName}} = %v, want 1", m.{{.AutoIncr.VarName}})
+    }
+}
+
+func Test{{$dao}}_InsertReturning(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{.Table.VarName}}()
+    mock.ExpectQuery(`INSERT INTO "{{$table}}" ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}"{{.Name}}"{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}${{add $i 1}}{{end}}) RETURNING "{{.AutoIncr.Name}}"`,
+        {{- range .InsertFields}} m.{{.VarName}},{{end}}).WillReturnRows([]string{ {{- quote .AutoIncr.Name -}} }, []interface{}{int64(2)})
+    if err := New{{$dao}}(db, PostgresDialect{}).Insert(context.Background(), m); err != nil {
+        t.Fatalf("{{$dao}}.Insert() error = %v", err)
+    }
+    if m.{{.AutoIncr.VarName}} != 2 {
+        t.Errorf("{{$dao}}.Insert() {{.AutoIncr.VarName}} = %v, want 2", m.{{.AutoIncr.VarName}})
+    }
+{{- end}}
+}
+
+func Test{{$dao}}_BatchInsert(t *testing.T) {
+    db, mock := newMockDB(t)
+    m1, m2 := newTest{{.Table.VarName}}(), newTest{{.Table.VarName}}()
+    mock.ExpectExec("INSERT INTO `{{$table}}` ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}`{{.Name}}`{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}}), ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}})",
+        {{- range .InsertFields}} m1.{{.VarName}},{{end}}{{range .InsertFields}} m2.{{.VarName}},{{end}}).WillReturnResult(0, 2)
+    affected, err := New{{$dao}}(db, MySQLDialect{}).BatchInsert(context.Background(), []*{{.ModelType}}{m1, m2})
+    if err != nil || affected != 2 {
+        t.Fatalf("{{$dao}}.BatchInsert() = %v, %v, want 2", affected, err)
+    }
+    if affected, err := New{{$dao}}(db, MySQLDialect{}).BatchInsert(context.Background(), nil); err != nil || affected != 0 {
+        t.Errorf("{{$dao}}.BatchInsert(nil) = %v, %v, want 0", affected, err)
+    }
+}
+
+// Test{{$dao}}_BatchInsertChunk 参数个数超过上限时分多条SQL插入，影响的行数累加
+func Test{{$dao}}_BatchInsertChunk(t *testing.T) {
+    defer func(old int) { maxBatchArgs = old }(maxBatchArgs)
+    maxBatchArgs = 2*len({{lowerCamel .Table.Name}}ColumnsInsert) + 1
+    db, mock := newMockDB(t)
+    m1, m2, m3 := newTest{{.Table.VarName}}(), newTest{{.Table.VarName}}(), newTest{{.Table.VarName}}()
+    mock.ExpectExec("INSERT INTO `{{$table}}` ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}`{{.Name}}`{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}}), ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}})",
+        {{- range .InsertFields}} m1.{{.VarName}},{{end}}{{range .InsertFields}} m2.{{.VarName}},{{end}}).WillReturnResult(0, 2)
+    mock.ExpectExec("INSERT INTO `{{$table}}` ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}`{{.Name}}`{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}})",
+        {{- range .InsertFields}} m3.{{.VarName}},{{end}}).WillReturnResult(0, 1)
+    affected, err := New{{$dao}}(db, MySQLDialect{}).BatchInsert(context.Background(), []*{{.ModelType}}{m1, m2, m3})
+    if err != nil || affected != 3 {
+        t.Fatalf("{{$dao}}.BatchInsert() = %v, %v, want 3", affected, err)
+    }
+}
+{{- if and .PKFields .UpdateFields}}
+
+func Test{{$dao}}_Update(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{.Table.VarName}}()
+    mock.ExpectExec("UPDATE `{{$table}}` SET {{range $i, $f := .UpdateFields}}{{if $i}}, {{end}}`{{.Name}}` = ?{{end}} WHERE {{range $i, $f := .PKFields}}{{if $i}} AND {{end}}`{{.Name}}` = ?{{end}}",
+        {{- range .UpdateFields}} m.{{.VarName}},{{end}}{{range .PKFields}} m.{{.VarName}},{{end}}).WillReturnResult(0, 1)
+    affected, err := New{{$dao}}(db, MySQLDialect{}).Update(context.Background(), m)
+    if err != nil || affected != 1 {
+        t.Fatalf("{{$dao}}.Update() = %v, %v, want 1", affected, err)
+    }
+}
+{{- end}}
+{{- if .PKFields}}
+
+func Test{{$dao}}_Delete(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{.Table.VarName}}()
+    mock.ExpectExec("DELETE FROM `{{$table}}` WHERE {{range $i, $f := .PKFields}}{{if $i}} AND {{end}}`{{.Name}}` = ?{{end}}",
+        {{- range .PKFields}} m.{{.VarName}},{{end}}).WillReturnResult(0, 1)
+    affected, err := New{{$dao}}(db, MySQLDialect{}).Delete(context.Background(), {{range .PKFields}}m.{{.VarName}}, {{end}})
+    if err != nil || affected != 1 {
+        t.Fatalf("{{$dao}}.Delete() = %v, %v, want 1", affected, err)
+    }
+}
+
+func Test{{$dao}}_GetByPK(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{.Table.VarName}}()
+    query := "SELECT {{range $i, $f := .Fields}}{{if $i}}, {{end}}`{{.Name}}`{{end}} FROM `{{$table}}` WHERE {{range $i, $f := .PKFields}}{{if $i}} AND {{end}}`{{.Name}}` = ?{{end}}"
+    mock.ExpectQuery(query, {{range .PKFields}}m.{{.VarName}}, {{end}}).WillReturnRows({{lowerCamel .Table.Name}}Columns, []interface{}{
+        {{- range .Fields}}m.{{.VarName}}, {{end -}} })
+    mock.ExpectQuery(query, {{range .PKFields}}m.{{.VarName}}, {{end}}).WillReturnRows({{lowerCamel .Table.Name}}Columns)
+    dao := New{{$dao}}(db, MySQLDialect{})
+    got, err := dao.GetByPK(context.Background(), {{range .PKFields}}m.{{.VarName}}, {{end}})
+    if err != nil {
+        t.Fatalf("{{$dao}}.GetByPK() error = %v", err)
+    }
+    if !reflect.DeepEqual(got, m) {
+        t.Errorf("{{$dao}}.GetByPK() = %+v, want %+v", got, m)
+    }
+    if _, err := dao.GetByPK(context.Background(), {{range .PKFields}}m.{{.VarName}}, {{end}}); !errors.Is(err, sql.ErrNoRows) {
+        t.Errorf("{{$dao}}.GetByPK() error = %v, want sql.ErrNoRows", err)
+    }
+}
+
+// Test{{$dao}}_Zero 零值记录可以写入，可为空的字段读到NULL时为零值或nil
+func Test{{$dao}}_Zero(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := &{{.ModelType}}{}
+    mock.ExpectExec("INSERT INTO `{{$table}}` ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}`{{.Name}}`{{end}}) VALUES ({{range $i, $f := .InsertFields}}{{if $i}}, {{end}}?{{end}})",
+        {{- range .InsertFields}} m.{{.VarName}},{{end}}).WillReturnResult(0, 1)
+    mock.ExpectQuery("SELECT {{range $i, $f := .Fields}}{{if $i}}, {{end}}`{{.Name}}`{{end}} FROM `{{$table}}` WHERE {{range $i, $f := .PKFields}}{{if $i}} AND {{end}}`{{.Name}}` = ?{{end}}",
+        {{- range .PKFields}} m.{{.VarName}},{{end}}).WillReturnRows({{lowerCamel .Table.Name}}Columns, []interface{}{
+        {{- range .Fields}}{{if .Nullable}}nil{{else}}m.{{.VarName}}{{end}}, {{end -}} })
+    dao := New{{$dao}}(db, MySQLDialect{})
+    if err := dao.Insert(context.Background(), m); err != nil {
+        t.Fatalf("{{$dao}}.Insert() error = %v", err)
+    }
+    got, err := dao.GetByPK(context.Background(), {{range .PKFields}}m.{{.VarName}}, {{end}})
+    if err != nil {
+        t.Fatalf("{{$dao}}.GetByPK() error = %v", err)
+    }
+    if !reflect.DeepEqual(got, m) {
+        t.Errorf("{{$dao}}.GetByPK() = %+v, want %+v", got, m)
+    }
+}
+{{- end}}
+{{- range .UniqueFields}}
+
+func Test{{$dao}}_FindBy{{.VarName}}(t *testing.T) {
+    db, mock := newMockDB(t)
+    m := newTest{{$.Table.VarName}}()
+{{- if .NullPtr}}
+    m.{{.VarName}} = new({{.Type}})
+{{- end}}
+    mock.ExpectQuery("SELECT {{range $i, $f := $.Fields}}{{if $i}}, {{end}}`{{.Name}}`{{end}} FROM `{{$table}}` WHERE `{{.Name}}` = ?", m.{{.VarName}}).
+        WillReturnRows({{lowerCamel $.Table.Name}}Columns, []interface{}{ {{- range $.Fields}}m.{{.VarName}}, {{end -}} })
+    got, err := New{{$dao}}(db, MySQLDialect{}).FindBy{{.VarName}}(context.Background(), {{if .NullPtr}}*{{end}}m.{{.VarName}})
+    if err != nil {
+        t.Fatalf("{{$dao}}.FindBy{{.VarName}}() error = %v", err)
+    }
+    if !reflect.DeepEqual(got, m) {
+        t.Errorf("{{$dao}}.FindBy{{.VarName}}() = %+v, want %+v", got, m)
+    }
+}
+{{- end}}
@@ -0,0 +1,245 @@
+// Package {{.PkgName}} 共享枚举定义，同一个枚举只定义一次
+package {{.PkgName}}
+{{range $enum := .Enums}}
+{{- $values := printf "%sValues" (lowerCamel .Name)}}
+// {{.Name}} {{.CName}}，使用该枚举的表：{{join ", " .Tables}}
+//
+// 未定义的枚举值(如数据库中新增了枚举值而程序未更新)可以通过Scan读取，通过Value、MarshalText以及MarshalJSON原样输出，
+// 需要时使用IsValid判断；Parse{{.Name}}、UnmarshalText以及UnmarshalJSON只接受已定义的枚举值
+type {{.Name}} {{.Type}}
+
+// {{.Name}}-{{.CName}}枚举定义
+const (
+    {{- range .EnumValues}}
+    // {{$enum.Name}}{{.VarName}} {{$enum.CName}}-{{.CName}}
+        {{- if .Offline}}
+    //
+    // Deprecated: 该枚举值已下线，仅用于兼容历史数据
+        {{- end}}
+        {{- if $enum.IsNum}}
+    {{$enum.Name}}{{.VarName}} {{$enum.Name}} = {{.Value}}
+        {{- else}}
+    {{$enum.Name}}{{.VarName}} {{$enum.Name}} = {{quote .Value}}
+        {{- end}}
+    {{- end}}
+)
+
+// {{$values}} {{.Name}}的所有枚举值，包含已下线的枚举值
+var {{$values}} = []struct {
+    value      {{.Name}}
+    name       string
+    desc       string
+    deprecated bool
+}{
+    {{- range .EnumValues}}
+    { {{- $enum.Name}}{{.VarName}}, {{quote (or .Name .Value)}}, {{quote .CName}}, {{.Offline -}} },
+    {{- end}}
+}
+
+// All{{pluralize .Name}} 返回{{.Name}}所有未下线的枚举值
+func All{{pluralize .Name}}() []{{.Name}} {
+    ret := make([]{{.Name}}, 0, len({{$values}}))
+    for _, v := range {{$values}} {
+        if !v.deprecated {
+            ret = append(ret, v.value)
+        }
+    }
+    return ret
+}
+
+// Parse{{.Name}} 根据枚举值或英文名解析{{.Name}}，已下线的枚举值也可以解析
+func Parse{{.Name}}(s string) ({{.Name}}, error) {
+    for _, v := range {{$values}} {
+        if v.value.text() == s || v.name == s {
+            return v.value, nil
+        }
+    }
+    var zero {{.Name}}
+    return zero, fmt.Errorf("invalid {{.Name}}(%s)", s)
+}
+
+// text 返回枚举值的字符串形式
+func (e {{.Name}}) text() string {
+    {{- if .IsNum}}
+    return strconv.FormatInt(int64(e), 10)
+    {{- else}}
+    return string(e)
+    {{- end}}
+}
+
+// known 是否为已定义的枚举值，包含已下线的枚举值
+func (e {{.Name}}) known() bool {
+    for _, v := range {{$values}} {
+        if v.value == e {
+            return true
+        }
+    }
+    return false
+}
+
+// isUnset 是否为未赋值的零值，零值为已定义的枚举值时不视为未赋值
+func (e {{.Name}}) isUnset() bool {
+    var zero {{.Name}}
+    return e == zero && !e.known()
+}
+
+// String 返回枚举值的英文名，未定义的枚举值返回{{.Name}}(值)
+func (e {{.Name}}) String() string {
+    for _, v := range {{$values}} {
+        if v.value == e {
+            return v.name
+        }
+    }
+    return "{{.Name}}(" + e.text() + ")"
+}
+
+// Desc 返回枚举值的描述
+func (e {{.Name}}) Desc() string {
+    for _, v := range {{$values}} {
+        if v.value == e {
+            return v.desc
+        }
+    }
+    return ""
+}
+
+// IsValid 是否为已定义且未下线的枚举值
+func (e {{.Name}}) IsValid() bool {
+    for _, v := range {{$values}} {
+        if v.value == e {
+            return !v.deprecated
+        }
+    }
+    return false
+}
+
+// IsDeprecated 是否为已下线的枚举值
+func (e {{.Name}}) IsDeprecated() bool {
+    for _, v := range {{$values}} {
+        if v.value == e {
+            return v.deprecated
+        }
+    }
+    return false
+}
+
+// MarshalText 实现encoding.TextMarshaler，输出枚举值，未定义的枚举值原样输出，未赋值的零值输出空字符串
+func (e {{.Name}}) MarshalText() ([]byte, error) {
+    if e.isUnset() {
+        return []byte{}, nil
+    }
+    return []byte(e.text()), nil
+}
+
+// UnmarshalText 实现encoding.TextUnmarshaler，支持枚举值以及英文名，空字符串解析为零值
+func (e *{{.Name}}) UnmarshalText(text []byte) error {
+    if len(text) == 0 {
+        var zero {{.Name}}
+        *e = zero
+        return nil
+    }
+    v, err := Parse{{.Name}}(string(text))
+    if err != nil {
+        return err
+    }
+    *e = v
+    return nil
+}
+{{- if .IsNum}}
+
+// MarshalJSON 实现json.Marshaler，输出数字，未定义的枚举值原样输出，未赋值的零值输出null
+func (e {{.Name}}) MarshalJSON() ([]byte, error) {
+    if e.isUnset() {
+        return []byte("null"), nil
+    }
+    return []byte(e.text()), nil
+}
+
+// UnmarshalJSON 实现json.Unmarshaler，支持数字以及枚举值或英文名的字符串，null解析为零值
+func (e *{{.Name}}) UnmarshalJSON(data []byte) error {
+    if string(data) == "null" {
+        var zero {{.Name}}
+        *e = zero
+        return nil
+    }
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        s = string(data)
+    }
+    return e.UnmarshalText([]byte(s))
+}
+{{- else}}
+
+// MarshalJSON 实现json.Marshaler，输出字符串，未定义的枚举值原样输出，未赋值的零值输出null
+func (e {{.Name}}) MarshalJSON() ([]byte, error) {
+    if e.isUnset() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(e.text())
+}
+
+// UnmarshalJSON 实现json.Unmarshaler，支持枚举值或英文名的字符串，null解析为零值
+func (e *{{.Name}}) UnmarshalJSON(data []byte) error {
+    if string(data) == "null" {
+        var zero {{.Name}}
+        *e = zero
+        return nil
+    }
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return fmt.Errorf("invalid {{.Name}}(%s): %w", data, err)
+    }
+    return e.UnmarshalText([]byte(s))
+}
+{{- end}}
+
+// Scan 实现sql.Scanner，已下线以及未定义的枚举值也可以读取，通过IsValid判断，NULL读取为零值
+func (e *{{.Name}}) Scan(src interface{}) error {
+    switch v := src.(type) {
+    case nil:
+        var zero {{.Name}}
+        *e = zero
+        return nil
+    {{- if .IsNum}}
+    case int64:
+        *e = {{.Name}}(v)
+        return nil
+    {{- end}}
+    case []byte:
+        return e.scanText(string(v))
+    case string:
+        return e.scanText(v)
+    }
+    return fmt.Errorf("cannot scan %T into {{.Name}}", src)
+}
+
+// scanText 读取数据库中的字符串，支持英文名{{if .IsNum}}，未定义的枚举值需为数字{{else}}，未定义的枚举值原样读取{{end}}
+func (e *{{.Name}}) scanText(s string) error {
+    if v, err := Parse{{.Name}}(s); err == nil {
+        *e = v
+        return nil
+    }
+    {{- if .IsNum}}
+    num, err := strconv.ParseInt(s, 10, 64)
+    if err != nil {
+        return fmt.Errorf("cannot scan %q into {{.Name}}", s)
+    }
+    *e = {{.Name}}(num)
+    {{- else}}
+    *e = {{.Name}}(s)
+    {{- end}}
+    return nil
+}
+
+// Value 实现driver.Valuer，未定义的枚举值原样写入，未赋值的零值写入NULL
+func (e {{.Name}}) Value() (driver.Value, error) {
+    if e.isUnset() {
+        return nil, nil
+    }
+    {{- if .IsNum}}
+    return int64(e), nil
+    {{- else}}
+    return string(e), nil
+    {{- end}}
+}
+{{end}}
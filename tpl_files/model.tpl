@@ -5,7 +5,7 @@ package {{.PkgName}}
 type {{.Table.VarName}} struct {
     {{- range .Fields}}
     // {{.VarName}} {{.CName}}
-    {{.VarName}} {{.Type}} `json:"{{.Name}}" xorm:"'{{.Name}}'" gorm:"column:{{.Name}}"`
+    {{.VarName}} {{if .NullPtr}}*{{end}}{{.Type}} `json:"{{.Name}}" xorm:"'{{.Name}}'" gorm:"column:{{.Name}}"`
     {{- end}}
 }
 
@@ -13,3 +13,6 @@ type {{.Table.VarName}} struct {
 func (*{{.Table.VarName}}) TableName() string {
     return "{{.Table.Name}}"
 }
+
+// metacenter:begin custom
+// metacenter:end custom
@@ -0,0 +1,24 @@
+// Package {{.PkgName}} {{.PkgName}}的表注册信息
+package {{.PkgName}}
+
+// 表英文名定义
+const (
+    {{- range .Tables}}
+    // Table{{.Table.VarName}} {{.Table.CName}}
+    Table{{.Table.VarName}} = "{{.Table.Name}}"
+    {{- end}}
+)
+
+// TableModels 表英文名->模型实例，用于按表名反射或批量注册orm模型
+var TableModels = map[string]interface{}{
+    {{- range .Tables}}
+    Table{{.Table.VarName}}: &{{.Table.VarName}}{},
+    {{- end}}
+}
+
+// TableCNames 表英文名->表中文名
+var TableCNames = map[string]string{
+    {{- range .Tables}}
+    Table{{.Table.VarName}}: {{quote .Table.CName}},
+    {{- end}}
+}
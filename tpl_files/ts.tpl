@@ -0,0 +1,32 @@
+{{- /* schema级模板，需指定Scope为schema，每个枚举只生成一次，表接口通过枚举类型名引用 */ -}}
+// 表以及枚举的TypeScript类型定义
+{{- range $enum := .Enums}}
+
+// {{.Name}} {{.CName}}枚举
+export const {{.Name}} = {
+    {{- range .EnumValues}}
+  // {{.CName}}
+  {{.VarName}}: {{if $enum.IsNum}}{{.Value}}{{else}}{{jsQuote .Value}}{{end}},
+    {{- end}}
+} as const;
+
+// {{.Name}}Value {{.CName}}枚举值的联合类型
+export type {{.Name}}Value = (typeof {{.Name}})[keyof typeof {{.Name}}];
+
+// {{.Name}}Labels {{.CName}}枚举值到描述的映射
+export const {{.Name}}Labels: Record<{{.Name}}Value, string> = {
+    {{- range .EnumValues}}
+  {{if $enum.IsNum}}{{.Value}}{{else}}{{jsQuote .Value}}{{end}}: {{jsQuote .CName}},
+    {{- end}}
+};
+{{- end}}
+{{- range .Tables}}
+
+// {{.Table.VarName}} {{.Table.CName}}
+export interface {{.Table.VarName}} {
+    {{- range .Fields}}
+  /** {{.CName}} */
+  {{.Name}}{{if .Nullable}}?{{end}}: {{if .EnumType}}{{.EnumType}}Value{{else}}{{.Type}}{{end}}{{if .Nullable}} | null{{end}};
+    {{- end}}
+}
+{{- end}}
@@ -0,0 +1,193 @@
+package metacenter
+
+import (
+	"context"
+	"encoding/json"
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/iancoleman/strcase"
+)
+
+// 英文名词的不规则复数形式
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"datum":  "data",
+	"foot":   "feet",
+	"man":    "men",
+	"mouse":  "mice",
+	"person": "people",
+	"tooth":  "teeth",
+	"woman":  "women",
+}
+
+// 单复数同形的名词
+var uncountableNouns = map[string]bool{
+	"data": true, "equipment": true, "info": true, "information": true, "metadata": true,
+	"news": true, "series": true, "sheep": true, "species": true,
+}
+
+// pluralize 将英文名的最后一个单词转换为复数形式，保留原有的大小写以及分隔符，如sub_task->sub_tasks，Category->Categories
+func pluralize(name string) string {
+	start := len(name)
+	for start > 0 && isLetterOrDigit(name[start-1]) {
+		start--
+	}
+	// 驼峰命名时只转换最后一个单词
+	for i := len(name) - 1; i > start; i-- {
+		if name[i] >= 'A' && name[i] <= 'Z' && name[i-1] >= 'a' && name[i-1] <= 'z' {
+			start = i
+			break
+		}
+	}
+	prefix, word := name[:start], name[start:]
+	lower := strings.ToLower(word)
+	if word == "" || uncountableNouns[lower] {
+		return name
+	}
+	if plural, ok := irregularPlurals[lower]; ok {
+		switch {
+		case len(word) > 1 && strings.ToUpper(word) == word:
+			plural = strings.ToUpper(plural)
+		case word[0] >= 'A' && word[0] <= 'Z':
+			plural = strings.ToUpper(plural[:1]) + plural[1:]
+		}
+		return prefix + plural
+	}
+	switch {
+	case strings.HasSuffix(lower, "s") || strings.HasSuffix(lower, "x") || strings.HasSuffix(lower, "z") ||
+		strings.HasSuffix(lower, "ch") || strings.HasSuffix(lower, "sh"):
+		return prefix + word + matchCase(word, "es")
+	case len(lower) > 1 && strings.HasSuffix(lower, "y") && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return prefix + word[:len(word)-1] + matchCase(word, "ies")
+	}
+	return prefix + word + matchCase(word, "s")
+}
+
+func isLetterOrDigit(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// matchCase 单词全大写时将后缀转换为大写，如STATUS->STATUSES
+func matchCase(word, suffix string) string {
+	if len(word) > 1 && strings.ToUpper(word) == word {
+		return strings.ToUpper(suffix)
+	}
+	return suffix
+}
+
+// escapeGoKeyword 名称为Go关键字或预声明标识符时加下划线后缀，如type->type_
+func escapeGoKeyword(name string) string {
+	if token.IsKeyword(name) || goPredeclared[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// goPredeclared 作为变量名会遮蔽预声明标识符的名称，见Go规范的Predeclared identifiers
+var goPredeclared = map[string]bool{
+	// 类型
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true,
+	"error": true, "float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true,
+	// 常量以及零值
+	"true": true, "false": true, "iota": true, "nil": true,
+	// 内置函数
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true, "copy": true, "delete": true,
+	"imag": true, "len": true, "make": true, "max": true, "min": true, "new": true, "panic": true,
+	"print": true, "println": true, "real": true, "recover": true,
+}
+
+// tplFieldValues 获取字段列表中每个字段的属性值，attr为Name/VarName/Type/CName
+func tplFieldValues(fields []TplField, attr string) []string {
+	values := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch attr {
+		case "VarName":
+			values = append(values, field.VarName)
+		case "Type":
+			values = append(values, field.Type)
+		case "CName":
+			values = append(values, field.CName)
+		default:
+			values = append(values, field.Name)
+		}
+	}
+	return values
+}
+
+// tplFuncMap 模板函数，genParam.Funcs中的同名函数会覆盖内置函数
+func (d *DefaultMetaCenter) tplFuncMap(ctx context.Context, genParam *GenerateGoFilesParam) template.FuncMap {
+	funcs := template.FuncMap{
+		// 命名转换
+		"snake":          strcase.ToSnake,
+		"camel":          strcase.ToCamel,
+		"lowerCamel":     strcase.ToLowerCamel,
+		"kebab":          strcase.ToKebab,
+		"screamingSnake": strcase.ToScreamingSnake,
+		"pluralize":      pluralize,
+		"lower":          strings.ToLower,
+		"upper":          strings.ToUpper,
+		"trimPrefix":     func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		// Go标识符
+		"escapeKeyword": escapeGoKeyword,
+		// goVar 转换为小驼峰并转义关键字，用于生成参数名以及局部变量名，如type->type_，task_id->taskId
+		"goVar": func(name string) string { return escapeGoKeyword(strcase.ToLowerCamel(name)) },
+		// 引号
+		"quote": strconv.Quote,
+		"quoteAll": func(values []string) []string {
+			quoted := make([]string, 0, len(values))
+			for _, value := range values {
+				quoted = append(quoted, strconv.Quote(value))
+			}
+			return quoted
+		},
+		"backquote": func(s string) string { return "`" + s + "`" },
+		// jsQuote 转换为JavaScript/TypeScript字符串字面量
+		"jsQuote": jsQuote,
+		// 字段列表
+		"join":        func(sep string, values []string) string { return strings.Join(values, sep) },
+		"fieldValues": tplFieldValues,
+		// joinPK 使用sep拼接主键字段的attr属性，如{{joinPK .PKFields "Name" ", "}}->id, name
+		"joinPK": func(fields []TplField, attr, sep string) string {
+			return strings.Join(tplFieldValues(fields, attr), sep)
+		},
+		// pkParams 主键字段作为函数参数，如id int64, name string
+		"pkParams": func(fields []TplField) string {
+			params := make([]string, 0, len(fields))
+			for _, field := range fields {
+				params = append(params, escapeGoKeyword(strcase.ToLowerCamel(field.Name))+" "+field.Type)
+			}
+			return strings.Join(params, ", ")
+		},
+		// add 整数相加，如{{add $i 1}}用于生成从1开始的编号
+		"add": func(a, b int) int { return a + b },
+		// 类型查询
+		// typeByID 根据数据类型ID获取模板中使用的类型名，指定了TypeConverter时为目标语言类型
+		"typeByID": func(typeID int) string { return d.getTplType(ctx, typeID, genParam) },
+		// typeByKind 根据逻辑类型(DataTypeXXX)获取模板中使用的类型名
+		"typeByKind": func(kind string) string {
+			converter := genParam.TypeConverter
+			if converter == nil {
+				converter = d.dataTypeGetter
+			}
+			if dataType := converter.GetByName(ctx, kind); dataType != nil && dataType.Name != "" {
+				return dataType.Name
+			}
+			return kind
+		},
+	}
+	for name, fn := range genParam.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// jsQuote 转换为JavaScript/TypeScript字符串字面量，JSON字符串即为合法的JS字符串，不会出现Go特有的\U等转义
+func jsQuote(s string) string {
+	body, _ := json.Marshal(s)
+	return string(body)
+}
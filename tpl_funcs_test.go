@@ -0,0 +1,80 @@
+package metacenter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func Test_pluralize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"task", "tasks"},
+		{"sub_task", "sub_tasks"},
+		{"SubTask", "SubTasks"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"status", "statuses"},
+		{"box", "boxes"},
+		{"branch", "branches"},
+		{"person", "people"},
+		{"Person", "People"},
+		{"user_info", "user_info"},
+		{"STATUS", "STATUSES"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pluralize(tt.name); got != tt.want {
+				t.Errorf("pluralize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultMetaCenter_tplFuncMap(t *testing.T) {
+	ctx := context.Background()
+	d := NewDefaultMetaCenter(ctx, WithDataTypeGetter(NewGolangDataTypeGetter()))
+	table := newGenTestTables()[0]
+	table.Fields = append(table.Fields, &Field{ID: 9, Name: "type", CName: "类型", Type: 6})
+	tests := []struct {
+		name  string
+		tpl   string
+		funcs template.FuncMap
+		want  string
+	}{
+		{"naming", `{{camel .Table.Name}} {{lowerCamel "task_status"}} {{kebab "task_status"}} {{snake "TaskStatus"}}`,
+			nil, "TTask taskStatus task-status task_status"},
+		{"pluralize", `{{pluralize (trimPrefix "t_" .Table.Name)}}`, nil, "tasks"},
+		{"keyword", `{{range .Fields}}{{goVar .Name}} {{end}}`, nil, "id taskStatus phase ext createTime type_ "},
+		{"quote", `{{quote .Table.CName}} {{backquote .Table.Name}}`, nil, "\"任务表\" `t_task`"},
+		{"pk", `{{joinPK .PKFields "Name" ", "}}|{{pkParams .PKFields}}`, nil, "id|id int64"},
+		{"join", `{{join "," (fieldValues .Fields "VarName")}}`, nil, "Id,TaskStatus,Phase,Ext,CreateTime,Type"},
+		{"type lookup", `{{typeByID 8}} {{typeByKind "datetime"}}`, nil, "decimal.Decimal utils.DateTime"},
+		{"kind lookup", `{{typeByKind "int"}} {{typeByKind "uint"}} {{typeByKind "float64"}} {{typeByKind "decimal"}} {{typeByKind "string"}}`,
+			nil, "int uint float64 decimal.Decimal string"},
+		{"predeclared", `{{goVar "any"}} {{goVar "comparable"}} {{goVar "clear"}} {{goVar "min"}} {{goVar "max"}} {{goVar "panic"}} {{goVar "uint8"}}`,
+			nil, "any_ comparable_ clear_ min_ max_ panic_ uint8_"},
+		{"user func override", `{{quote "x"}} {{hello}}`,
+			template.FuncMap{"quote": func(s string) string { return "'" + s + "'" }, "hello": func() string { return "hi" }},
+			"'x' hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genParam := &GenerateGoFilesParam{Name: "test", OutputDirPath: "model", Funcs: tt.funcs}
+			tpl, err := template.New("test").Funcs(d.tplFuncMap(ctx, genParam)).Parse(tt.tpl)
+			if err != nil {
+				t.Fatalf("parse template error = %v", err)
+			}
+			got := &strings.Builder{}
+			if err := tpl.Execute(got, d.getTplParam(ctx, table, genParam, nil)); err != nil {
+				t.Fatalf("execute template error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("template output = %v, want %v", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package metacenter
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerateGoFilesParam_readTpl(t *testing.T) {
+	bundle := fstest.MapFS{
+		"go/model.tpl": &fstest.MapFile{Data: []byte("bundle model")},
+		"dao.tmpl":     &fstest.MapFile{Data: []byte("bundle dao")},
+	}
+	tests := []struct {
+		name       string
+		param      *GenerateGoFilesParam
+		wantSource string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{"default by name", &GenerateGoFilesParam{Name: "model"}, "model.tpl", "// Package {{.PkgName}}", false},
+		{"default by tpl name", &GenerateGoFilesParam{Name: "x", TplName: "const"}, "const.tpl", "// Package {{.PkgName}}", false},
+		{"file path override", &GenerateGoFilesParam{Name: "model", TplName: "const", TplFilePath: "./tpl_files/ts.tpl"},
+			"./tpl_files/ts.tpl", "", false},
+		{"fs bundle", &GenerateGoFilesParam{Name: "model", TplName: "go/model", TplFS: bundle}, "go/model.tpl", "bundle model", false},
+		{"fs bundle with ext", &GenerateGoFilesParam{Name: "dao", TplName: "dao.tmpl", TplFS: bundle}, "dao.tmpl", "bundle dao", false},
+		{"not found", &GenerateGoFilesParam{Name: "unknown"}, "unknown.tpl", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, body, err := tt.param.readTpl()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateGoFilesParam.readTpl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if source != tt.wantSource {
+				t.Errorf("GenerateGoFilesParam.readTpl() source = %v, want %v", source, tt.wantSource)
+			}
+			if !strings.HasPrefix(string(body), tt.wantPrefix) {
+				t.Errorf("GenerateGoFilesParam.readTpl() body = %v, want prefix %v", string(body), tt.wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package utils 生成代码使用到的工具类型
+package utils
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// DateTimeLayout DateTime序列化使用的格式
+const DateTimeLayout = "2006-01-02 15:04:05"
+
+// DateTime 日期时间，JSON序列化为2006-01-02 15:04:05格式的本地时间，零值序列化为null
+// GolangDataTypeGetter中日期时间字段的类型
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime 由time.Time创建DateTime
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{Time: t}
+}
+
+// String 按DateTimeLayout格式化
+func (t DateTime) String() string {
+	return t.Format(DateTimeLayout)
+}
+
+// MarshalJSON 实现json.Marshaler
+func (t DateTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.Format(DateTimeLayout) + `"`), nil
+}
+
+// UnmarshalJSON 实现json.Unmarshaler，支持null以及空字符串
+func (t *DateTime) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" || s == `""` {
+		t.Time = time.Time{}
+		return nil
+	}
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return fmt.Errorf("invalid datetime(%s)", s)
+	}
+	parsed, err := time.ParseInLocation(DateTimeLayout, s[1:len(s)-1], time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid datetime(%s): %w", s, err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Scan 实现sql.Scanner，支持time.Time以及DateTimeLayout格式的字符串
+func (t *DateTime) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v
+	case []byte:
+		return t.scanString(string(v))
+	case string:
+		return t.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into DateTime", src)
+	}
+	return nil
+}
+
+func (t *DateTime) scanString(s string) error {
+	parsed, err := time.ParseInLocation(DateTimeLayout, s, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid datetime(%s): %w", s, err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Value 实现driver.Valuer，零值写入NULL
+func (t DateTime) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return t.Time, nil
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateTime_JSON(t *testing.T) {
+	tm := NewDateTime(time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local))
+	tests := []struct {
+		name string
+		t    DateTime
+		want string
+	}{
+		{"value", tm, `"2024-01-02 10:00:00"`},
+		{"zero", DateTime{}, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.t)
+			if err != nil || string(got) != tt.want {
+				t.Fatalf("json.Marshal() = %s, %v, want %s", got, err, tt.want)
+			}
+			var parsed DateTime
+			if err := json.Unmarshal(got, &parsed); err != nil || !parsed.Equal(tt.t.Time) {
+				t.Errorf("json.Unmarshal() = %v, %v, want %v", parsed, err, tt.t)
+			}
+		})
+	}
+	var parsed DateTime
+	if err := json.Unmarshal([]byte(`"2024/01/02"`), &parsed); err == nil {
+		t.Errorf("json.Unmarshal() with invalid layout should return error")
+	}
+}
+
+func TestDateTime_Scan(t *testing.T) {
+	want := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    time.Time
+		wantErr bool
+	}{
+		{"time", want, want, false},
+		{"string", "2024-01-02 10:00:00", want, false},
+		{"bytes", []byte("2024-01-02 10:00:00"), want, false},
+		{"nil", nil, time.Time{}, false},
+		{"int", 1, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDateTime(time.Now())
+			if err := got.Scan(tt.src); (err != nil) != tt.wantErr {
+				t.Fatalf("DateTime.Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("DateTime.Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if v, err := (DateTime{}).Value(); v != nil || err != nil {
+		t.Errorf("DateTime.Value() of zero = %v, %v, want nil", v, err)
+	}
+}